@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+)
+
+/**
+ * runCommand executes a maintenance command given on the command line instead
+ * of starting the server. The database must already be connected.
+ *
+ * Commands:
+ *   migrate                              - only runs the migrations
+ *   backup <file.zip>                    - exports the catalog
+ *   restore <file.zip> [--mode=merge]    - restores the catalog (replace or merge)
+ *
+ * @returns - Whether a command was recognized and executed
+ */
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "migrate":
+		log.Println("Migrações aplicadas.")
+	case "backup":
+		if len(args) < 2 {
+			log.Fatal("Uso: backup <arquivo.zip>")
+		}
+		runBackup(args[1])
+	case "restore":
+		if len(args) < 2 {
+			log.Fatal("Uso: restore <arquivo.zip> [--mode=replace|merge]")
+		}
+		mode := service.RestoreModeMerge
+		for _, arg := range args[2:] {
+			if strings.HasPrefix(arg, "--mode=") {
+				mode = service.RestoreMode(strings.TrimPrefix(arg, "--mode="))
+			}
+		}
+		runRestore(args[1], mode)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func runBackup(path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("Erro ao criar %s: %v", path, err)
+	}
+	defer f.Close()
+
+	manifest, err := service.ExportBackup(f)
+	if err != nil {
+		log.Fatalf("Erro ao gerar backup: %v", err)
+	}
+
+	fmt.Printf("Backup salvo em %s: %v\n", path, manifest.Counts)
+}
+
+func runRestore(path string, mode service.RestoreMode) {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Erro ao abrir %s: %v", path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		log.Fatalf("Erro ao ler %s: %v", path, err)
+	}
+
+	report, err := service.RestoreBackup(f, info.Size(), mode)
+	if err != nil {
+		log.Fatalf("Erro ao restaurar backup: %v", err)
+	}
+
+	fmt.Printf("Backup restaurado (%s): %+v\n", report.Mode, *report.Result)
+}
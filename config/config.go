@@ -36,7 +36,7 @@ func LoadEnv() {
  * @param db The GORM database instance.
  */
 func MigrateDB(db *gorm.DB) {
-	err := db.AutoMigrate(&model.User{}, &model.Category{}, &model.Product{}, &model.Promotion{})
+	err := db.AutoMigrate(&model.User{}, &model.Category{}, &model.Product{}, &model.Promotion{}, &model.SlugHistory{}, &model.ProductRevision{}, &model.Tag{}, &model.Review{}, &model.Favorite{}, &model.ProductViewDaily{}, &model.ProductCoView{}, &model.ExchangeRate{}, &model.Coupon{}, &model.CouponRedemption{})
 	if err != nil {
 		log.Fatalf("Erro ao migrar o banco de dados: %v", err)
 	}
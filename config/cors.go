@@ -16,7 +16,8 @@ func CORSMiddleware() gin.HandlerFunc {
 	config := cors.Config{
 		AllowOrigins:     filterEmpty([]string{frontendURL, "https://larifazcroche.vercel.app"}),
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Requested-With", "XMLHttpRequest"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Requested-With", "XMLHttpRequest", "If-Match"},
+		ExposeHeaders:    []string{"ETag"}, // o painel lê o ETag para enviar If-Match nas edições
 		AllowCredentials: true,
 	}
 
@@ -77,22 +77,37 @@ func Delete(key string) error {
 	return RedisClient.Del(ctx, key).Err()
 }
 
-// DeletePattern remove todas as chaves que correspondem ao padrão
+// scanBatchSize é quantas chaves o Redis examina por chamada de SCAN
+const scanBatchSize = 500
+
+/**
+ * DeletePattern removes every key matching the pattern. Keys are walked with
+ * SCAN instead of KEYS, so Redis is never blocked while a large keyspace is
+ * inspected; matches are deleted batch by batch.
+ *
+ * @param pattern - A glob-style pattern, e.g. "products*"
+ */
 func DeletePattern(pattern string) error {
 	if RedisClient == nil {
 		return nil // Redis não disponível, não é erro
 	}
 
-	keys, err := RedisClient.Keys(ctx, pattern).Result()
-	if err != nil {
-		return err
+	var cursor uint64
+	for {
+		keys, next, err := RedisClient.Scan(ctx, cursor, pattern, scanBatchSize).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := RedisClient.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
 	}
-
-	if len(keys) > 0 {
-		return RedisClient.Del(ctx, keys...).Err()
-	}
-
-	return nil
 }
 
 // GenerateKey gera uma chave de cache baseada em parâmetros
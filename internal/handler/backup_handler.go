@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+)
+
+// ExportBackup gera o zip de backup do catálogo para download (admin)
+func ExportBackup(c *gin.Context) {
+	var buf bytes.Buffer
+	if _, err := service.ExportBackup(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar backup: " + err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("backup-%s.zip", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// RestoreBackup restaura o catálogo a partir de um zip enviado no campo "file"; ?mode=replace|merge (admin)
+func RestoreBackup(c *gin.Context) {
+	mode := service.RestoreMode(c.DefaultQuery("mode", string(service.RestoreModeMerge)))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo de backup não encontrado: " + err.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao abrir o arquivo: " + err.Error()})
+		return
+	}
+	defer file.Close()
+
+	report, err := service.RestoreBackup(file, fileHeader.Size, mode)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
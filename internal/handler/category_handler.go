@@ -1,21 +1,30 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
-	"os"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
 )
 
 // CreateCategory cria uma nova categoria (exige token de admin)
 func CreateCategory(c *gin.Context) {
 	var req struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Image       string `json:"image"`
+		Name            string                `json:"name"`
+		Description     string                `json:"description"`
+		Image           string                `json:"image"`
+		ParentID        *uint                 `json:"parentId"`
+		Hidden          bool                  `json:"hidden"`
+		SEOTitle        string                `json:"seoTitle"`
+		SEODescription  string                `json:"seoDescription"`
+		AttributeSchema model.AttributeSchema `json:"attributeSchema"`
+		Translations    model.Translations    `json:"translations"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -24,12 +33,22 @@ func CreateCategory(c *gin.Context) {
 	}
 
 	category := model.Category{
-		Name:        req.Name,
-		Description: req.Description,
-		Image:       req.Image,
+		Name:            req.Name,
+		Description:     req.Description,
+		Image:           req.Image,
+		ParentID:        req.ParentID,
+		Hidden:          req.Hidden,
+		SEOTitle:        req.SEOTitle,
+		SEODescription:  req.SEODescription,
+		AttributeSchema: req.AttributeSchema,
+		Translations:    req.Translations,
 	}
 
 	if err := service.CreateCategory(&category); err != nil {
+		if errors.Is(err, service.ErrInvalidCategory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar categoria: " + err.Error()})
 		return
 	}
@@ -37,7 +56,7 @@ func CreateCategory(c *gin.Context) {
 	c.JSON(http.StatusCreated, category)
 }
 
-// DeleteCategory deleta uma categoria (exige token de admin)
+// DeleteCategory deleta uma categoria; com subcategorias exige ?children=reparent|delete e com produtos ?moveTo=<id> ou ?cascade=true (exige token de admin)
 func DeleteCategory(c *gin.Context) {
 	categoryIDStr := c.Param("id")
 	categoryID, err := strconv.ParseUint(categoryIDStr, 10, 64)
@@ -46,17 +65,41 @@ func DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	if err := service.DeleteCategory(uint(categoryID)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao deletar categoria: " + err.Error()})
+	children := service.ChildrenAction(c.Query("children"))
+	if children != "" && children != service.ChildrenReparent && children != service.ChildrenDelete {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "children inválido: use reparent ou delete"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Categoria deletada com sucesso!"})
+	opts := service.CategoryDeleteOptions{Children: children, CascadeProducts: c.Query("cascade") == "true"}
+	if moveTo := c.Query("moveTo"); moveTo != "" {
+		targetID, err := strconv.ParseUint(moveTo, 10, 64)
+		if err != nil || targetID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "moveTo inválido"})
+			return
+		}
+		opts.MoveProductsTo = uint(targetID)
+	}
+
+	result, err := service.DeleteCategory(uint(categoryID), opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrCategoryHasChildren), errors.Is(err, service.ErrCategoryHasProducts):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidCategoryTarget):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao deletar categoria: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Categoria deletada com sucesso!", "result": result})
 }
 
 // GetCategories retorna todas as categorias (público)
 func GetCategories(c *gin.Context) {
-	categories, err := service.GetCategories()
+	categories, err := service.GetCategories(requestLocale(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter categorias: " + err.Error()})
 		return
@@ -145,14 +188,7 @@ func DeleteCategoryImage(c *gin.Context) {
 		return
 	}
 
-	// Remove a imagem do sistema de arquivos
-	localPath := "./uploads" + imagePath
-	if err := os.Remove(localPath); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover a imagem do sistema de arquivos: " + err.Error()})
-		return
-	}
-
-	// Remove o caminho da imagem do banco de dados
+	// Remove o caminho da imagem do banco de dados; o arquivo só é apagado se não estiver em uso
 	if err := service.RemoveCategoryImage(uint(categoryID)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover imagem do banco de dados: " + err.Error()})
 		return
@@ -161,7 +197,19 @@ func DeleteCategoryImage(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Imagem da categoria deletada com sucesso!"})
 }
 
-// UpdateCategory atualiza os dados de uma categoria existente (exige token de admin)
+// categoryPatch é o conteúdo editável de uma categoria, usado como documento base do JSON Merge Patch
+type categoryPatch struct {
+	Name           string             `json:"name"`
+	Description    string             `json:"description"`
+	Image          string             `json:"image"`
+	ParentID       *uint              `json:"parentId"`
+	Hidden         bool               `json:"hidden"`
+	SEOTitle       string             `json:"seoTitle"`
+	SEODescription string             `json:"seoDescription"`
+	Translations   model.Translations `json:"translations"`
+}
+
+// UpdateCategory aplica um JSON Merge Patch a uma categoria; com If-Match, responde 412 se ela mudou (exige token de admin)
 func UpdateCategory(c *gin.Context) {
 	// Obtém o ID da categoria da URL
 	categoryIDStr := c.Param("id")
@@ -171,32 +219,203 @@ func UpdateCategory(c *gin.Context) {
 		return
 	}
 
-	// Define a estrutura de dados para atualizar a categoria
-	var req struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Image       string `json:"image"`
+	expectedVersion, err := ifMatchVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	// Valida os dados recebidos
-	if err := c.ShouldBindJSON(&req); err != nil {
+	existing, err := repository.GetCategoryByID(uint(categoryID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter categoria: " + err.Error()})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Categoria não encontrada"})
+		return
+	}
+	if expectedVersion != 0 && expectedVersion != existing.Version {
+		c.Header("ETag", versionETag(existing.Version))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": repository.ErrVersionConflict.Error()})
+		return
+	}
+
+	// Aplica somente os campos enviados sobre os dados atuais
+	current, err := json.Marshal(categoryPatch{
+		Name:           existing.Name,
+		Description:    existing.Description,
+		Image:          existing.Image,
+		ParentID:       existing.ParentID,
+		Hidden:         existing.Hidden,
+		SEOTitle:       existing.SEOTitle,
+		SEODescription: existing.SEODescription,
+		Translations:   existing.Translations,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	merged, _, err := util.MergePatch(current, body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req categoryPatch
+	if err := json.Unmarshal(merged, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Cria a categoria com os dados recebidos
 	updatedCategory := model.Category{
-		Name:        req.Name,
-		Description: req.Description,
-		Image:       req.Image,
+		Name:           req.Name,
+		Description:    req.Description,
+		Image:          req.Image,
+		ParentID:       req.ParentID,
+		Hidden:         req.Hidden,
+		SEOTitle:       req.SEOTitle,
+		SEODescription: req.SEODescription,
+		Version:        existing.Version,
+	}
+	// O documento mesclado sempre traz as traduções; um mapa vazio remove todas
+	updatedCategory.Translations = req.Translations
+	if updatedCategory.Translations == nil {
+		updatedCategory.Translations = model.Translations{}
 	}
 
 	// Chama o serviço para atualizar a categoria no banco de dados
 	if err := service.UpdateCategory(uint(categoryID), &updatedCategory); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCategory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar categoria: " + err.Error()})
 		return
 	}
 
 	// Retorna a categoria atualizada
+	c.Header("ETag", versionETag(updatedCategory.Version))
 	c.JSON(http.StatusOK, updatedCategory)
 }
+
+// GetAdminCategories lista todas as categorias, inclusive as ocultas; ?tree=true devolve a hierarquia (admin)
+func GetAdminCategories(c *gin.Context) {
+	var categories []model.Category
+	var err error
+	if c.Query("tree") == "true" {
+		categories, err = service.GetAdminCategoryTree()
+	} else {
+		categories, err = service.GetAdminCategories()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter categorias: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// ReorderCategories define a ordem das categorias de um mesmo nível (admin)
+func ReorderCategories(c *gin.Context) {
+	var req struct {
+		ParentID    *uint  `json:"parentId"`
+		CategoryIDs []uint `json:"categoryIds" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := service.ReorderCategories(req.ParentID, req.CategoryIDs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao reordenar categorias: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ordem das categorias atualizada com sucesso!"})
+}
+
+// GetCategoryTree retorna as categorias aninhadas em children, a partir das de primeiro nível (público)
+func GetCategoryTree(c *gin.Context) {
+	tree, err := service.GetCategoryTree(requestLocale(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter categorias: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// GetCategoryBySlug retorna uma categoria pelo slug; slugs antigos respondem 301 com o slug atual
+func GetCategoryBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	category, redirectSlug, err := service.GetCategoryBySlug(slug, requestLocale(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if category == nil {
+		c.Header("Location", "/categories/slug/"+redirectSlug)
+		c.JSON(http.StatusMovedPermanently, gin.H{"slug": redirectSlug, "redirect": true})
+		return
+	}
+
+	c.Header("ETag", versionETag(category.Version))
+	c.JSON(http.StatusOK, category)
+}
+
+// GetCategoryAttributeSchema retorna os campos de atributo da categoria, usados para montar formulários e filtros (público)
+func GetCategoryAttributeSchema(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da categoria inválido"})
+		return
+	}
+
+	schema, err := service.GetCategoryAttributeSchema(uint(categoryID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categoryId": categoryID, "fields": schema})
+}
+
+// UpdateCategoryAttributeSchema substitui os campos de atributo da categoria (exige token de admin)
+func UpdateCategoryAttributeSchema(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da categoria inválido"})
+		return
+	}
+
+	var req struct {
+		Fields model.AttributeSchema `json:"fields"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Fields == nil {
+		req.Fields = model.AttributeSchema{}
+	}
+
+	category, err := service.UpdateCategoryAttributeSchema(uint(categoryID), req.Fields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao atualizar atributos: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categoryId": category.ID, "fields": category.AttributeSchema})
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+)
+
+// couponRequest é o corpo de POST /coupons/validate e /coupons/redeem
+type couponRequest struct {
+	Code  string               `json:"code" binding:"required"`
+	Items []service.CouponItem `json:"items" binding:"required"`
+}
+
+// respondCouponError responde 404 para cupom inexistente, 422 para cupom que não vale no pedido e 400 para itens inválidos
+func respondCouponError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCouponNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCouponInvalid):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCouponOrderInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao aplicar cupom: " + err.Error()})
+	}
+}
+
+// ValidateCoupon confere um código contra os itens do pedido e devolve o desconto, sem registrar uso (usuário logado)
+func ValidateCoupon(c *gin.Context) {
+	var req couponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := service.ValidateCoupon(req.Code, c.GetUint("userID"), req.Items)
+	if err != nil {
+		respondCouponError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+// RedeemCoupon confere o código e registra o uso, respeitando os limites mesmo com pedidos simultâneos (usuário logado)
+func RedeemCoupon(c *gin.Context) {
+	var req couponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := service.RedeemCoupon(req.Code, c.GetUint("userID"), req.Items)
+	if err != nil {
+		respondCouponError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, quote)
+}
+
+// GetCoupons lista os cupons (admin)
+func GetCoupons(c *gin.Context) {
+	coupons, err := service.GetCoupons()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter cupons: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, coupons)
+}
+
+// GetCoupon retorna um cupom pelo ID (admin)
+func GetCoupon(c *gin.Context) {
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do cupom inválido"})
+		return
+	}
+
+	coupon, err := service.GetCoupon(uint(couponID))
+	if err != nil {
+		if errors.Is(err, service.ErrCouponNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter cupom: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, coupon)
+}
+
+// CreateCoupon cadastra um cupom (admin)
+func CreateCoupon(c *gin.Context) {
+	var req model.Coupon
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := service.CreateCoupon(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, req)
+}
+
+// UpdateCoupon substitui os dados de um cupom (admin)
+func UpdateCoupon(c *gin.Context) {
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do cupom inválido"})
+		return
+	}
+
+	var req model.Coupon
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := service.UpdateCoupon(uint(couponID), &req); err != nil {
+		if errors.Is(err, service.ErrCouponNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// DeleteCoupon deleta um cupom (admin)
+func DeleteCoupon(c *gin.Context) {
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do cupom inválido"})
+		return
+	}
+
+	if err := service.DeleteCoupon(uint(couponID)); err != nil {
+		if errors.Is(err, service.ErrCouponNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao deletar cupom: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cupom deletado com sucesso!"})
+}
+
+// GetCouponRedemptions lista os usos de um cupom (admin)
+func GetCouponRedemptions(c *gin.Context) {
+	couponID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do cupom inválido"})
+		return
+	}
+
+	redemptions, err := service.GetCouponRedemptions(uint(couponID))
+	if err != nil {
+		if errors.Is(err, service.ErrCouponNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter usos do cupom: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, redemptions)
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+)
+
+/**
+ * requestExchangeRate resolves the ?currency= parameter of public product
+ * responses. It returns nil for BRL (or no parameter) and writes the error
+ * response itself when the currency is unknown or has no rate yet.
+ */
+func requestExchangeRate(c *gin.Context) (*model.ExchangeRate, bool) {
+	rate, err := service.GetCurrentExchangeRate(c.Query("currency"))
+	switch {
+	case errors.Is(err, service.ErrExchangeRateNotFound):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return nil, false
+	case errors.Is(err, service.ErrUnsupportedCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter cotação: " + err.Error()})
+		return nil, false
+	}
+	return rate, true
+}
+
+// applyPricing aplica os descontos das promoções e converte os preços dos produtos de uma resposta paginada
+func applyPricing(response *model.PaginatedResponse, rate *model.ExchangeRate) {
+	if products, ok := response.Data.([]model.Product); ok {
+		service.ApplyPricing(products, rate)
+	}
+}
+
+// GetCurrentExchangeRates lista a cotação em vigor de cada moeda (público)
+func GetCurrentExchangeRates(c *gin.Context) {
+	rates, err := service.GetCurrentExchangeRates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter cotações: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"baseCurrency": model.BaseCurrency, "rates": rates})
+}
+
+// GetExchangeRates lista o histórico de cotações, opcionalmente filtrado por ?currency= (admin)
+func GetExchangeRates(c *gin.Context) {
+	rates, err := service.GetExchangeRates(c.Query("currency"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter cotações: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// SaveExchangeRate cadastra ou substitui a cotação de uma moeda a partir de uma data (admin)
+func SaveExchangeRate(c *gin.Context) {
+	var req service.ExchangeRateInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rate, err := service.SaveExchangeRate(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// DeleteExchangeRate remove a cotação de uma moeda em uma data (admin)
+func DeleteExchangeRate(c *gin.Context) {
+	if err := service.DeleteExchangeRate(c.Param("currency"), c.Param("date")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Cotação removida com sucesso!"})
+}
+
+/**
+ * ImportExchangeRates imports rates from a CSV sent as the multipart field
+ * "file" or as the raw request body (admin). dryRun=true validates only.
+ */
+func ImportExchangeRates(c *gin.Context) {
+	dryRun := c.Query("dryRun") == "true" || c.PostForm("dryRun") == "true"
+
+	var reader io.Reader
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo CSV não encontrado: " + err.Error()})
+			return
+		}
+		if fileHeader.Size > maxImportSize {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Arquivo CSV muito grande"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao abrir o arquivo: " + err.Error()})
+			return
+		}
+		defer file.Close()
+		reader = file
+	} else {
+		reader = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	}
+
+	report, err := service.ImportExchangeRatesCSV(reader, dryRun)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao importar cotações: " + err.Error()})
+		return
+	}
+
+	if !report.DryRun && !report.Imported {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
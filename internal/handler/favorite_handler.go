@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+)
+
+// AddFavorite adiciona um produto aos favoritos do usuário logado
+func AddFavorite(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("productId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	if err := service.AddFavorite(c.GetUint("userID"), uint(productID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Erro ao favoritar produto: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Produto adicionado aos favoritos!"})
+}
+
+// RemoveFavorite remove um produto dos favoritos do usuário logado
+func RemoveFavorite(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("productId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	if err := service.RemoveFavorite(c.GetUint("userID"), uint(productID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao remover favorito: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Produto removido dos favoritos!"})
+}
+
+// GetMyFavorites lista os produtos favoritos do usuário logado, paginados
+func GetMyFavorites(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	rate, ok := requestExchangeRate(c)
+	if !ok {
+		return
+	}
+
+	favorites, err := service.GetUserFavorites(c.GetUint("userID"), page, limit, requestLocale(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter favoritos: " + err.Error()})
+		return
+	}
+	applyPricing(favorites, rate)
+
+	c.JSON(http.StatusOK, favorites)
+}
+
+// GetMostFavoritedReport lista os produtos mais favoritados (admin)
+func GetMostFavoritedReport(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	report, err := service.GetMostFavoritedReport(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar relatório: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+)
+
+// maxImportSize limita o tamanho do arquivo CSV aceito (10 MB)
+const maxImportSize = 10 << 20
+
+/**
+ * ImportProducts imports products from a CSV sent as the multipart field
+ * "file" or as the raw request body (admin).
+ * Query options: dryRun=true validates only; createCategories=true creates
+ * unknown categories instead of rejecting the row.
+ */
+func ImportProducts(c *gin.Context) {
+	opts := service.ImportOptions{
+		DryRun:           c.Query("dryRun") == "true" || c.PostForm("dryRun") == "true",
+		CreateCategories: c.Query("createCategories") == "true" || c.PostForm("createCategories") == "true",
+	}
+
+	var reader io.Reader
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo CSV não encontrado: " + err.Error()})
+			return
+		}
+		if fileHeader.Size > maxImportSize {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Arquivo CSV muito grande"})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao abrir o arquivo: " + err.Error()})
+			return
+		}
+		defer file.Close()
+		reader = file
+	} else {
+		reader = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	}
+
+	report, err := service.ImportProductsCSV(reader, opts)
+	if err != nil {
+		// Um produto foi salvo por outra pessoa durante a importação; nada foi gravado
+		if errors.Is(err, repository.ErrVersionConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Erro ao importar produtos: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao importar produtos: " + err.Error()})
+		return
+	}
+
+	if !report.DryRun && !report.Imported {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
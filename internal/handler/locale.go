@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+)
+
+// requestLocale retorna o idioma resolvido pelo middleware Locale, ou o padrão
+func requestLocale(c *gin.Context) string {
+	if locale := c.GetString("locale"); locale != "" {
+		return locale
+	}
+	return model.DefaultLocale
+}
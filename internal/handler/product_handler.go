@@ -1,24 +1,40 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
 )
 
 func CreateProduct(c *gin.Context) {
 	var req struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Image       string `json:"image"`
-		Price       string `json:"price"`
-		CategoryID  uint   `json:"categoryId"`
+		Name          string                  `json:"name"`
+		Description   string                  `json:"description"`
+		Image         string                  `json:"image"`
+		Price         string                  `json:"price"`
+		CategoryID    uint                    `json:"categoryId"`
+		Status        string                  `json:"status"`
+		PublishAt     *time.Time              `json:"publishAt"`
+		UnpublishAt   *time.Time              `json:"unpublishAt"`
+		Featured      bool                    `json:"featured"`
+		Tags          []string                `json:"tags"`
+		Availability  string                  `json:"availability"`
+		StockQuantity int                     `json:"stockQuantity"`
+		LeadTimeDays  int                     `json:"leadTimeDays"`
+		Attributes    model.ProductAttributes `json:"attributes"`
+		Translations  model.Translations      `json:"translations"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -27,14 +43,30 @@ func CreateProduct(c *gin.Context) {
 	}
 
 	product := model.Product{
-		Name:        req.Name,
-		Description: req.Description,
-		ImageUrls:   req.Image,
-		PriceRange:  req.Price,
-		CategoryID:  req.CategoryID,
+		Name:          req.Name,
+		Description:   req.Description,
+		ImageUrls:     req.Image,
+		PriceRange:    req.Price,
+		CategoryID:    req.CategoryID,
+		Status:        model.ProductStatus(req.Status),
+		PublishAt:     req.PublishAt,
+		UnpublishAt:   req.UnpublishAt,
+		Featured:      req.Featured,
+		Availability:  model.ProductAvailability(req.Availability),
+		StockQuantity: req.StockQuantity,
+		LeadTimeDays:  req.LeadTimeDays,
+		Attributes:    req.Attributes,
+		Translations:  req.Translations,
+	}
+	for _, name := range req.Tags {
+		product.Tags = append(product.Tags, model.Tag{Name: name})
 	}
 
 	if err := service.CreateProduct(&product); err != nil {
+		if errors.Is(err, service.ErrInvalidProduct) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao criar produto: " + err.Error()})
 		return
 	}
@@ -122,6 +154,24 @@ func UploadProductImages(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// productPatch é o conteúdo editável de um produto, usado como documento base do JSON Merge Patch
+type productPatch struct {
+	Name         string                  `json:"name"`
+	Description  string                  `json:"description"`
+	Image        string                  `json:"image"`
+	Price        string                  `json:"price"`
+	CategoryID   uint                    `json:"categoryId"`
+	Tags         []string                `json:"tags"`
+	Attributes   model.ProductAttributes `json:"attributes"`
+	Translations model.Translations      `json:"translations"`
+}
+
+/**
+ * UpdateProduct applies a JSON Merge Patch (RFC 7386) to a product: only the
+ * fields sent change, null clears a field and attributes are merged key by
+ * key. Send the ETag from the last read in If-Match to get 412 instead of
+ * overwriting someone else's changes.
+ */
 func UpdateProduct(c *gin.Context) {
 	productIDStr := c.Param("id")
 	productID, err := strconv.ParseUint(productIDStr, 10, 64)
@@ -130,21 +180,55 @@ func UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	existingProduct, err := repository.GetProductByID(uint(productID))
+	expectedVersion, err := ifMatchVersion(c)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existingProduct, err := repository.GetProductWithTags(uint(productID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter produto: " + err.Error()})
+		return
+	}
+	if existingProduct == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Produto não encontrado"})
 		return
 	}
+	if expectedVersion != 0 && expectedVersion != existingProduct.Version {
+		c.Header("ETag", versionETag(existingProduct.Version))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": repository.ErrVersionConflict.Error()})
+		return
+	}
 
-	var req struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Image       string `json:"image"`
-		Price       string `json:"price"`
-		CategoryID  uint   `json:"categoryId"`
+	current, err := json.Marshal(productPatch{
+		Name:         existingProduct.Name,
+		Description:  existingProduct.Description,
+		Image:        existingProduct.ImageUrls,
+		Price:        existingProduct.PriceRange,
+		CategoryID:   existingProduct.CategoryID,
+		Tags:         model.TagNames(existingProduct.Tags),
+		Attributes:   existingProduct.Attributes,
+		Translations: existingProduct.Translations,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	merged, touched, err := util.MergePatch(current, body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req productPatch
+	if err := json.Unmarshal(merged, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -152,21 +236,49 @@ func UpdateProduct(c *gin.Context) {
 	product := model.Product{
 		Name:        req.Name,
 		Description: req.Description,
+		ImageUrls:   req.Image,
 		PriceRange:  req.Price,
 		CategoryID:  req.CategoryID,
+		Version:     existingProduct.Version,
 	}
-
-	if req.Image != "" {
-		product.ImageUrls = req.Image
-	} else {
-		product.ImageUrls = existingProduct.ImageUrls
+	// O documento mesclado sempre traz as traduções; um mapa vazio remove todas
+	product.Translations = req.Translations
+	if product.Translations == nil {
+		product.Translations = model.Translations{}
+	}
+	// Atributos não enviados mantêm os valores atuais (ou são podados se a categoria mudar)
+	if touched["attributes"] {
+		product.Attributes = req.Attributes
+		if product.Attributes == nil {
+			product.Attributes = model.ProductAttributes{}
+		}
+	}
+	// Tags enviadas (mesmo null ou vazias) substituem as atuais junto com o restante do produto
+	if touched["tags"] {
+		product.Tags = make([]model.Tag, 0, len(req.Tags))
+		for _, name := range req.Tags {
+			product.Tags = append(product.Tags, model.Tag{Name: name})
+		}
 	}
 
 	if err := service.UpdateProduct(uint(productID), &product); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidProduct) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar produto: " + err.Error()})
 		return
 	}
 
+	if !touched["tags"] {
+		product.Tags = existingProduct.Tags
+	}
+
+	c.Header("ETag", versionETag(product.Version))
 	c.JSON(http.StatusOK, product)
 }
 
@@ -203,12 +315,19 @@ func SearchProducts(c *gin.Context) {
 	limit, _ := strconv.Atoi(limitStr)
 
 	// Usa a nova função com metadados de paginação
-	paginatedResponse, err := service.SearchProductsWithMetadata(searchTerm, page, limit)
+	filter := parseProductFilter(c, model.SortName)
+	rate, ok := requestExchangeRate(c)
+	if !ok {
+		return
+	}
+
+	paginatedResponse, err := service.SearchProductsWithMetadata(searchTerm, page, limit, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar produtos: " + err.Error()})
 		return
 	}
 
+	applyPricing(paginatedResponse, rate)
 	c.JSON(http.StatusOK, paginatedResponse)
 }
 
@@ -220,12 +339,19 @@ func GetProducts(c *gin.Context) {
 	limit, _ := strconv.Atoi(limitStr)
 
 	// Usa a nova função com metadados de paginação
-	paginatedResponse, err := service.GetPaginatedProductsWithMetadata(page, limit)
+	filter := parseProductFilter(c, model.SortName)
+	rate, ok := requestExchangeRate(c)
+	if !ok {
+		return
+	}
+
+	paginatedResponse, err := service.GetPaginatedProductsWithMetadata(page, limit, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter produtos: " + err.Error()})
 		return
 	}
 
+	applyPricing(paginatedResponse, rate)
 	c.JSON(http.StatusOK, paginatedResponse)
 }
 
@@ -237,15 +363,101 @@ func GetProductsByCategory(c *gin.Context) {
 		return
 	}
 
-	products, err := service.GetProductsByCategory(uint(categoryID))
+	// Dentro da categoria a ordem padrão é a definida manualmente pelo admin
+	filter := parseProductFilter(c, model.SortManual)
+	rate, ok := requestExchangeRate(c)
+	if !ok {
+		return
+	}
+
+	products, err := service.GetProductsByCategory(uint(categoryID), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter produtos da categoria: " + err.Error()})
 		return
 	}
+	service.ApplyPricing(products, rate)
 
 	c.JSON(http.StatusOK, products)
 }
 
+// parseProductFilter lê os parâmetros de ordenação e filtro das listagens públicas (?sort= e ?tag=)
+func parseProductFilter(c *gin.Context, defaultSort model.ProductSort) model.ProductFilter {
+	return model.ProductFilter{
+		Sort: model.ParseProductSort(c.Query("sort"), defaultSort),
+		Tag:  util.Slugify(c.Query("tag")),
+		// Valores desconhecidos são ignorados
+		Availability: parseAvailabilityFilter(c.Query("availability")),
+		Attributes:   parseAttributeFilters(c),
+		Locale:       requestLocale(c),
+		// Só faz diferença na listagem por categoria
+		IncludeDescendants: c.Query("includeDescendants") == "true",
+	}
+}
+
+// maxAttributeFilters limita quantos filtros de atributo uma listagem aceita
+const maxAttributeFilters = 10
+
+/**
+ * parseAttributeFilters reads attribute filters from the query string:
+ * attr.<key>=value matches a value and attr.<key>.min / attr.<key>.max
+ * restrict numeric attributes to a range.
+ */
+func parseAttributeFilters(c *gin.Context) []model.AttributeFilter {
+	byKey := map[string]*model.AttributeFilter{}
+	for param, values := range c.Request.URL.Query() {
+		if !strings.HasPrefix(param, "attr.") || len(values) == 0 || values[0] == "" {
+			continue
+		}
+		key := strings.TrimPrefix(param, "attr.")
+		bound := ""
+		if i := strings.LastIndex(key, "."); i >= 0 {
+			key, bound = key[:i], key[i+1:]
+		}
+		if key == "" {
+			continue
+		}
+
+		filter := byKey[key]
+		if filter == nil {
+			if len(byKey) == maxAttributeFilters {
+				continue
+			}
+			filter = &model.AttributeFilter{Key: key}
+			byKey[key] = filter
+		}
+
+		switch bound {
+		case "":
+			filter.Value = values[0]
+		case "min", "max":
+			number, err := strconv.ParseFloat(strings.Replace(values[0], ",", ".", 1), 64)
+			if err != nil {
+				continue
+			}
+			if bound == "min" {
+				filter.Min = &number
+			} else {
+				filter.Max = &number
+			}
+		}
+	}
+
+	filters := make([]model.AttributeFilter, 0, len(byKey))
+	for _, filter := range byKey {
+		filters = append(filters, *filter)
+	}
+	sort.Slice(filters, func(i, j int) bool { return filters[i].Key < filters[j].Key })
+	return filters
+}
+
+// parseAvailabilityFilter retorna a disponibilidade pedida ou vazio quando o valor é desconhecido
+func parseAvailabilityFilter(value string) model.ProductAvailability {
+	if availability := model.ProductAvailability(value); availability.IsValid() {
+		return availability
+	}
+	return ""
+}
+
 func GetProductImages(c *gin.Context) {
 	productIDStr := c.Param("id")
 	log.Printf("Recebendo requisição para imagens do produto ID: %s", productIDStr)
@@ -264,6 +476,8 @@ func GetProductImages(c *gin.Context) {
 		return
 	}
 
+	service.TrackProductView(uint(productID), model.ViewKindImage, c.ClientIP(), c.Request.UserAgent())
+
 	log.Printf("Enviando imagens para o frontend: %v", images)
 	c.JSON(http.StatusOK, images)
 }
@@ -283,3 +497,314 @@ func GetUploadProgress(c *gin.Context) {
 		"progress":  progress,
 	})
 }
+
+// GetProductBySlug retorna um produto pelo slug; slugs antigos respondem 301 com o slug atual
+func GetProductBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+	rate, ok := requestExchangeRate(c)
+	if !ok {
+		return
+	}
+
+	product, redirectSlug, err := service.GetProductBySlug(slug, requestLocale(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if product == nil {
+		c.Header("Location", "/products/slug/"+redirectSlug)
+		c.JSON(http.StatusMovedPermanently, gin.H{"slug": redirectSlug, "redirect": true})
+		return
+	}
+
+	service.TrackProductView(product.ID, model.ViewKindDetail, c.ClientIP(), c.Request.UserAgent())
+	service.ApplyProductPricing(product, rate)
+	c.JSON(http.StatusOK, product)
+}
+
+// UpdateProductStatus altera o status e o agendamento de publicação de um produto (admin)
+func UpdateProductStatus(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	var req struct {
+		Status      string     `json:"status" binding:"required"`
+		PublishAt   *time.Time `json:"publishAt"`
+		UnpublishAt *time.Time `json:"unpublishAt"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := service.UpdateProductStatus(uint(productID), model.ProductStatus(req.Status), req.PublishAt, req.UnpublishAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao atualizar status: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// UpdateProductAvailability altera a disponibilidade, o estoque e o prazo de produção (admin)
+func UpdateProductAvailability(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	var req struct {
+		Availability  string `json:"availability" binding:"required"`
+		StockQuantity int    `json:"stockQuantity"`
+		LeadTimeDays  int    `json:"leadTimeDays"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	product, err := service.UpdateProductAvailability(uint(productID), model.ProductAvailability(req.Availability), req.StockQuantity, req.LeadTimeDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao atualizar disponibilidade: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// AdjustProductStock soma delta ao estoque; valores negativos dão baixa sem deixar o estoque negativo (admin)
+func AdjustProductStock(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	var req struct {
+		Delta int `json:"delta" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stock, err := service.AdjustProductStock(uint(productID), req.Delta)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": "Erro ao ajustar estoque: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"productId": productID, "stockQuantity": stock})
+}
+
+// GetAdminProducts lista produtos em qualquer status (rascunhos, arquivados e agendados inclusos)
+func GetAdminProducts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	paginatedResponse, err := service.GetAdminProductsWithMetadata(c.Query("status"), c.Query("search"), page, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao obter produtos: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, paginatedResponse)
+}
+
+// GetAdminProduct retorna um produto pelo ID, independente do status
+func GetAdminProduct(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	product, err := repository.GetProductByID(uint(productID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter produto: " + err.Error()})
+		return
+	}
+	if product == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Produto não encontrado"})
+		return
+	}
+
+	c.Header("ETag", versionETag(product.Version))
+	c.JSON(http.StatusOK, product)
+}
+
+// GetProductRevisions lista as revisões de um produto com o diff por campo (admin)
+func GetProductRevisions(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	revisions, err := service.GetProductRevisions(uint(productID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter revisões: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// RestoreProductRevision restaura um produto para uma revisão anterior (admin)
+func RestoreProductRevision(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Número da revisão inválido"})
+		return
+	}
+
+	product, err := service.RestoreProductRevision(uint(productID), revision)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao restaurar revisão: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// DuplicateProduct cria uma cópia em rascunho de um produto (admin)
+func DuplicateProduct(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	product, err := service.DuplicateProduct(uint(productID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao duplicar produto: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, product)
+}
+
+// GetRelatedProducts retorna recomendações "você também pode gostar" para um produto
+func GetRelatedProducts(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultRelatedLimit)))
+	rate, ok := requestExchangeRate(c)
+	if !ok {
+		return
+	}
+
+	products, err := service.GetRelatedProducts(uint(productID), limit, requestLocale(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	service.ApplyPricing(products, rate)
+
+	c.JSON(http.StatusOK, products)
+}
+
+// GetFeaturedProducts retorna os produtos em destaque (público)
+func GetFeaturedProducts(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "12"))
+	rate, ok := requestExchangeRate(c)
+	if !ok {
+		return
+	}
+
+	products, err := service.GetFeaturedProducts(limit, requestLocale(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter produtos em destaque: " + err.Error()})
+		return
+	}
+	service.ApplyPricing(products, rate)
+
+	c.JSON(http.StatusOK, products)
+}
+
+// SetProductFeatured marca ou desmarca um produto como destaque (admin)
+func SetProductFeatured(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	var req struct {
+		Featured *bool `json:"featured" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := service.SetProductFeatured(uint(productID), *req.Featured); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao atualizar destaque: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Destaque atualizado com sucesso!", "featured": *req.Featured})
+}
+
+// ReorderCategoryProducts recebe a lista ordenada de IDs de produtos de uma categoria (admin)
+func ReorderCategoryProducts(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da categoria inválido"})
+		return
+	}
+
+	var req struct {
+		ProductIDs []uint `json:"productIds" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := service.ReorderCategoryProducts(uint(categoryID), req.ProductIDs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao reordenar produtos: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Ordem dos produtos atualizada com sucesso!"})
+}
+
+// BulkUpdateProducts aplica uma ação a vários produtos de uma vez (admin)
+func BulkUpdateProducts(c *gin.Context) {
+	var op model.BulkProductOperation
+	if err := c.ShouldBindJSON(&op); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := service.BulkUpdateProducts(op)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro na operação em lote: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,7 +14,7 @@ import (
 
 // GET /promotion (público)
 func GetPromotion(c *gin.Context) {
-	p, err := service.GetActivePromotion()
+	p, err := service.GetActivePromotion(requestLocale(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter promoção: " + err.Error()})
 		return
@@ -23,15 +26,30 @@ func GetPromotion(c *gin.Context) {
 	c.JSON(http.StatusOK, p)
 }
 
-// PUT /promotion (admin)
+// PUT /promotion (admin): formato legado, altera a promoção em vigor ou a última editada
 func UpdatePromotion(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payload inválido: " + err.Error()})
+		return
+	}
 	var req model.Promotion
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := json.Unmarshal(body, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payload inválido: " + err.Error()})
+		return
+	}
+	// Campos ausentes do corpo mantêm os valores gravados
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Payload inválido: " + err.Error()})
 		return
 	}
+	sent := make(map[string]bool, len(fields))
+	for key := range fields {
+		sent[key] = true
+	}
 
-	saved, err := service.SavePromotion(&req)
+	saved, err := service.SavePromotion(&req, sent)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}) // 400 para validações
 		return
@@ -39,3 +57,97 @@ func UpdatePromotion(c *gin.Context) {
 
 	c.JSON(http.StatusOK, saved)
 }
+
+// GetAdminPromotions lista todas as promoções com a situação de cada uma (admin)
+func GetAdminPromotions(c *gin.Context) {
+	promotions, err := service.GetPromotions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter promoções: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, promotions)
+}
+
+// GetAdminPromotion retorna uma promoção pelo ID (admin)
+func GetAdminPromotion(c *gin.Context) {
+	promotionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da promoção inválido"})
+		return
+	}
+
+	p, err := service.GetPromotion(uint(promotionID))
+	if err != nil {
+		if errors.Is(err, service.ErrPromotionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter promoção: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+// CreateAdminPromotion cadastra uma nova promoção (admin)
+func CreateAdminPromotion(c *gin.Context) {
+	var req model.Promotion
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payload inválido: " + err.Error()})
+		return
+	}
+
+	if err := service.CreatePromotion(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, req)
+}
+
+// UpdateAdminPromotion substitui os dados de uma promoção (admin)
+func UpdateAdminPromotion(c *gin.Context) {
+	promotionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da promoção inválido"})
+		return
+	}
+
+	var req model.Promotion
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payload inválido: " + err.Error()})
+		return
+	}
+
+	if err := service.UpdatePromotion(uint(promotionID), &req); err != nil {
+		if errors.Is(err, service.ErrPromotionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}) // 400 para validações
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// DeleteAdminPromotion deleta uma promoção (admin)
+func DeleteAdminPromotion(c *gin.Context) {
+	promotionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da promoção inválido"})
+		return
+	}
+
+	if err := service.DeletePromotion(uint(promotionID)); err != nil {
+		if errors.Is(err, service.ErrPromotionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao deletar promoção: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Promoção deletada com sucesso!"})
+}
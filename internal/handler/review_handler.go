@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+)
+
+// GetProductReviews lista as avaliações aprovadas de um produto (público)
+func GetProductReviews(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	reviews, err := service.GetProductReviews(uint(productID), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter avaliações: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+// CreateReview publica uma avaliação do usuário logado; aceita JSON ou multipart com fotos em photos[]
+func CreateReview(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	var req struct {
+		Rating int    `json:"rating" form:"rating" binding:"required"`
+		Text   string `json:"text" form:"text"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var photos []*multipart.FileHeader
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		if form, err := c.MultipartForm(); err == nil {
+			photos = form.File["photos[]"]
+		}
+	}
+
+	review, err := service.CreateReview(uint(productID), c.GetUint("userID"), req.Rating, req.Text, photos)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao enviar avaliação: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Avaliação enviada! Ela aparecerá após a moderação.", "review": review})
+}
+
+// GetMyReviews lista as avaliações do usuário logado com o status de moderação
+func GetMyReviews(c *gin.Context) {
+	reviews, err := service.GetUserReviews(c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter avaliações: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+// GetReviewQueue lista avaliações para moderação; ?status=pending|approved|rejected|all (admin)
+func GetReviewQueue(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	reviews, err := service.GetReviewQueue(c.Query("status"), page, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao obter avaliações: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
+
+// ApproveReview aprova uma avaliação (admin)
+func ApproveReview(c *gin.Context) {
+	moderateReview(c, model.ReviewStatusApproved)
+}
+
+// RejectReview rejeita uma avaliação (admin)
+func RejectReview(c *gin.Context) {
+	moderateReview(c, model.ReviewStatusRejected)
+}
+
+func moderateReview(c *gin.Context, status model.ReviewStatus) {
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da avaliação inválido"})
+		return
+	}
+
+	review, err := service.ModerateReview(uint(reviewID), status)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao moderar avaliação: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
+
+// ReplyReview responde publicamente a uma avaliação (admin)
+func ReplyReview(c *gin.Context) {
+	reviewID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da avaliação inválido"})
+		return
+	}
+
+	var req struct {
+		Reply string `json:"reply"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := service.ReplyReview(uint(reviewID), req.Reply)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao responder avaliação: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, review)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+)
+
+// GetTags lista as tags com a quantidade de produtos visíveis (público)
+func GetTags(c *gin.Context) {
+	tags, err := service.GetTags()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter tags: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// CreateTag cria uma tag (admin)
+func CreateTag(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := service.CreateTag(req.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao criar tag: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// UpdateTag renomeia uma tag (admin)
+func UpdateTag(c *gin.Context) {
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da tag inválido"})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := service.RenameTag(uint(tagID), req.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao atualizar tag: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag remove uma tag de todos os produtos (admin)
+func DeleteTag(c *gin.Context) {
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da tag inválido"})
+		return
+	}
+
+	if err := service.DeleteTag(uint(tagID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao deletar tag: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deletada com sucesso!"})
+}
+
+// MergeTags unifica a tag da URL na tag informada em targetId (admin)
+func MergeTags(c *gin.Context) {
+	sourceID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da tag inválido"})
+		return
+	}
+
+	var req struct {
+		TargetID uint `json:"targetId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := service.MergeTags(uint(sourceID), req.TargetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao unificar tags: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tags unificadas com sucesso!", "tag": tag})
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+)
+
+// GetMissingTranslations lista os textos ainda sem tradução, opcionalmente de um idioma (admin)
+func GetMissingTranslations(c *gin.Context) {
+	locale := c.Query("locale")
+	if locale != "" && (locale == model.DefaultLocale || !model.IsSupportedLocale(locale)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Idioma inválido para o relatório: " + locale})
+		return
+	}
+
+	report, err := service.GetMissingTranslations(locale)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar relatório: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
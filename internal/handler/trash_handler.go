@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+)
+
+// GetTrash lista produtos e categorias deletados (admin)
+func GetTrash(c *gin.Context) {
+	trash, err := service.GetTrash()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao obter lixeira: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
+
+// RestoreProduct tira um produto da lixeira (admin)
+func RestoreProduct(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	if err := service.RestoreProduct(uint(productID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao restaurar produto: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Produto restaurado com sucesso!"})
+}
+
+// RestoreCategory tira uma categoria da lixeira; ?withProducts=true restaura também os produtos deletados com ela (admin)
+func RestoreCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da categoria inválido"})
+		return
+	}
+
+	withProducts := c.Query("withProducts") == "true"
+
+	restored, remaining, err := service.RestoreCategory(uint(categoryID), withProducts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao restaurar categoria: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Categoria restaurada com sucesso!",
+		"restoredProducts":  restored,
+		"remainingProducts": remaining,
+	})
+}
+
+// PurgeProduct remove permanentemente um produto da lixeira (admin)
+func PurgeProduct(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID do produto inválido"})
+		return
+	}
+
+	if err := service.PurgeProduct(uint(productID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao remover produto: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Produto removido permanentemente!"})
+}
+
+// PurgeCategory remove permanentemente uma categoria da lixeira (admin)
+func PurgeCategory(c *gin.Context) {
+	categoryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da categoria inválido"})
+		return
+	}
+
+	if err := service.PurgeCategory(uint(categoryID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Erro ao remover categoria: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Categoria removida permanentemente!"})
+}
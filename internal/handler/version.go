@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// versionETag monta o ETag de um registro a partir da sua versão
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+/**
+ * ifMatchVersion reads the version the client last saw from If-Match. An
+ * absent header or "*" returns 0, meaning the update is not checked, so
+ * clients that do not send the header keep working.
+ *
+ * @returns - The expected version, or an error when the header is malformed
+ */
+func ifMatchVersion(c *gin.Context) (int, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	value = strings.Trim(strings.TrimPrefix(value, "W/"), `"`)
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, errors.New("If-Match inválido: envie o ETag recebido na leitura")
+	}
+	return version, nil
+}
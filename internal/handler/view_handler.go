@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/service"
+)
+
+// GetViewReport retorna as visualizações diárias e os produtos mais vistos (admin)
+func GetViewReport(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	report, err := service.GetViewReport(days, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar relatório: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
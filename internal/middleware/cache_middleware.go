@@ -24,7 +24,8 @@ func CacheControl(maxAge int, isPublic bool) gin.HandlerFunc {
 		}
 
 		c.Header("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, maxAge))
-		c.Header("Vary", "Accept-Encoding")
+		// Respostas públicas são traduzidas conforme Accept-Language
+		c.Header("Vary", "Accept-Encoding, Accept-Language")
 		c.Next()
 	}
 }
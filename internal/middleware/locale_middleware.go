@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
+)
+
+/**
+ * Locale resolves the language of the request from the lang query parameter
+ * or the Accept-Language header and stores it in the context under "locale".
+ * The chosen locale is echoed in Content-Language.
+ */
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := util.ResolveLocale(c.Query("lang"), c.GetHeader("Accept-Language"))
+		c.Set("locale", locale)
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
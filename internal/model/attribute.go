@@ -0,0 +1,192 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AttributeType define o tipo de um campo do esquema de atributos
+type AttributeType string
+
+const (
+	// AttributeText é um texto livre (ex.: composição)
+	AttributeText AttributeType = "text"
+	// AttributeNumber é um número sem unidade (ex.: quantidade de peças)
+	AttributeNumber AttributeType = "number"
+	// AttributeEnum é uma escolha entre as opções definidas (ex.: tamanho P, M, G)
+	AttributeEnum AttributeType = "enum"
+	// AttributeUnit é um número medido na unidade definida no campo (ex.: altura em cm)
+	AttributeUnit AttributeType = "unit"
+)
+
+// IsValid indica se o tipo é um dos valores conhecidos
+func (t AttributeType) IsValid() bool {
+	switch t {
+	case AttributeText, AttributeNumber, AttributeEnum, AttributeUnit:
+		return true
+	}
+	return false
+}
+
+// IsNumeric indica se os valores do tipo são números
+func (t AttributeType) IsNumeric() bool {
+	return t == AttributeNumber || t == AttributeUnit
+}
+
+// AttributeField descreve um campo do esquema de atributos de uma categoria
+type AttributeField struct {
+	Key      string        `json:"key"`
+	Label    string        `json:"label"`
+	Type     AttributeType `json:"type"`
+	Required bool          `json:"required"`
+	Options  []string      `json:"options,omitempty"` // apenas enum
+	Unit     string        `json:"unit,omitempty"`    // apenas unit
+	Min      *float64      `json:"min,omitempty"`     // apenas number e unit
+	Max      *float64      `json:"max,omitempty"`     // apenas number e unit
+}
+
+/**
+ * AttributeSchema is the ordered list of custom fields products of a category
+ * may fill in, e.g. size and chest measurement for wearables or height and
+ * fill type for amigurumi. The order is the order of the admin form.
+ */
+type AttributeSchema []AttributeField
+
+// ProductAttributes guarda os valores dos atributos de um produto, indexados pela chave do campo
+type ProductAttributes map[string]interface{}
+
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// Field retorna o campo com a chave informada
+func (s AttributeSchema) Field(key string) (AttributeField, bool) {
+	for _, f := range s {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return AttributeField{}, false
+}
+
+// Validate confere se o esquema é consistente antes de ser salvo
+func (s AttributeSchema) Validate() error {
+	seen := map[string]bool{}
+	for i, f := range s {
+		if !attributeKeyPattern.MatchString(f.Key) {
+			return fmt.Errorf("campo %d: chave %q inválida (use letras minúsculas, números e _)", i+1, f.Key)
+		}
+		if seen[f.Key] {
+			return fmt.Errorf("campo %q repetido", f.Key)
+		}
+		seen[f.Key] = true
+
+		if strings.TrimSpace(f.Label) == "" {
+			return fmt.Errorf("campo %q: label é obrigatório", f.Key)
+		}
+		if !f.Type.IsValid() {
+			return fmt.Errorf("campo %q: tipo inválido (use text, number, enum ou unit)", f.Key)
+		}
+		if f.Type == AttributeEnum && len(f.Options) == 0 {
+			return fmt.Errorf("campo %q: enum precisa de options", f.Key)
+		}
+		if f.Type == AttributeUnit && strings.TrimSpace(f.Unit) == "" {
+			return fmt.Errorf("campo %q: unit precisa da unidade", f.Key)
+		}
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return fmt.Errorf("campo %q: min maior que max", f.Key)
+		}
+	}
+	return nil
+}
+
+/**
+ * ValidateValues checks product attribute values against the schema and
+ * returns them normalized: numbers sent as strings become numbers and empty
+ * values are dropped.
+ *
+ * @param values - The values sent by the admin
+ * @param requireAll - Whether missing required fields are an error
+ */
+func (s AttributeSchema) ValidateValues(values ProductAttributes, requireAll bool) (ProductAttributes, error) {
+	normalized := ProductAttributes{}
+	for key, value := range values {
+		field, ok := s.Field(key)
+		if !ok {
+			return nil, fmt.Errorf("atributo %q não existe nesta categoria", key)
+		}
+		if value == nil || value == "" {
+			continue
+		}
+
+		switch {
+		case field.Type.IsNumeric():
+			number, err := attributeNumber(value)
+			if err != nil {
+				return nil, fmt.Errorf("atributo %q deve ser numérico", key)
+			}
+			if field.Min != nil && number < *field.Min {
+				return nil, fmt.Errorf("atributo %q deve ser no mínimo %g", key, *field.Min)
+			}
+			if field.Max != nil && number > *field.Max {
+				return nil, fmt.Errorf("atributo %q deve ser no máximo %g", key, *field.Max)
+			}
+			normalized[key] = number
+		default:
+			text, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("atributo %q deve ser texto", key)
+			}
+			text = strings.TrimSpace(text)
+			if field.Type == AttributeEnum && !containsString(field.Options, text) {
+				return nil, fmt.Errorf("atributo %q deve ser um de: %s", key, strings.Join(field.Options, ", "))
+			}
+			if text != "" {
+				normalized[key] = text
+			}
+		}
+	}
+
+	if requireAll {
+		for _, field := range s {
+			if _, ok := normalized[field.Key]; field.Required && !ok {
+				return nil, fmt.Errorf("atributo %q é obrigatório", field.Key)
+			}
+		}
+	}
+	return normalized, nil
+}
+
+// Prune remove os valores cujas chaves não existem no esquema
+func (s AttributeSchema) Prune(values ProductAttributes) ProductAttributes {
+	pruned := ProductAttributes{}
+	for key, value := range values {
+		if _, ok := s.Field(key); ok {
+			pruned[key] = value
+		}
+	}
+	return pruned
+}
+
+// attributeNumber converte um valor JSON (número ou texto, com vírgula ou ponto) em float64
+func attributeNumber(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(strings.Replace(strings.TrimSpace(v), ",", ".", 1), 64)
+	}
+	return 0, errors.New("valor não numérico")
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package model
+
+// BulkAction define a operação aplicada a vários produtos de uma vez
+type BulkAction string
+
+const (
+	// BulkDelete move os produtos para a lixeira
+	BulkDelete BulkAction = "delete"
+	// BulkRestore tira os produtos da lixeira
+	BulkRestore BulkAction = "restore"
+	// BulkMoveCategory move os produtos para outra categoria
+	BulkMoveCategory BulkAction = "move_category"
+	// BulkChangeStatus altera o status de publicação dos produtos
+	BulkChangeStatus BulkAction = "change_status"
+	// BulkAddTags adiciona tags aos produtos, mantendo as atuais
+	BulkAddTags BulkAction = "add_tags"
+	// BulkRemoveTags remove tags dos produtos
+	BulkRemoveTags BulkAction = "remove_tags"
+	// BulkAdjustPrice reajusta os preços por um percentual
+	BulkAdjustPrice BulkAction = "adjust_price"
+)
+
+// IsValid indica se a ação é um dos valores conhecidos
+func (a BulkAction) IsValid() bool {
+	switch a {
+	case BulkDelete, BulkRestore, BulkMoveCategory, BulkChangeStatus, BulkAddTags, BulkRemoveTags, BulkAdjustPrice:
+		return true
+	}
+	return false
+}
+
+/**
+ * BulkProductOperation is the body of POST /admin/products/bulk. Only the
+ * fields used by the chosen action are read: CategoryID for move_category,
+ * Status for change_status, Tags for add_tags and remove_tags and Percent
+ * (e.g. 10 or -5.5) for adjust_price.
+ */
+type BulkProductOperation struct {
+	Action     BulkAction    `json:"action"`
+	ProductIDs []uint        `json:"productIds"`
+	CategoryID uint          `json:"categoryId"`
+	Status     ProductStatus `json:"status"`
+	Tags       []string      `json:"tags"`
+	Percent    float64       `json:"percent"`
+}
+
+// BulkItemResult é o resultado da operação em lote para um produto
+type BulkItemResult struct {
+	ProductID  uint   `json:"productId"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	PriceRange string `json:"priceRange,omitempty"` // novo preço, apenas adjust_price
+}
+
+// BulkResult resume uma operação em lote
+type BulkResult struct {
+	Action    BulkAction       `json:"action"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
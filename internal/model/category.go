@@ -5,7 +5,43 @@ import "gorm.io/gorm"
 type Category struct {
 	gorm.Model
 	Name        string    `json:"name"`
+	Slug        string    `json:"slug" gorm:"uniqueIndex:idx_category_slug"`
 	Description string    `json:"description"`
 	Image       string    `json:"image"`
-	Products    []Product `json:"products" gorm:"foreignKey:CategoryID"`
+	Products    []Product `json:"products" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+
+	// ParentID aponta a categoria mãe; nil para categorias de primeiro nível
+	ParentID *uint `json:"parentId" gorm:"index:idx_category_parent"`
+
+	// Children só é preenchido na árvore de categorias
+	Children []Category `json:"children,omitempty" gorm:"-"`
+
+	// Position é a ordem manual entre categorias irmãs
+	Position int `json:"position" gorm:"default:0"`
+
+	// Hidden tira a categoria (e suas subcategorias) da vitrine sem deletá-la; o admin continua vendo
+	Hidden bool `json:"hidden" gorm:"default:false"`
+
+	// SEOTitle e SEODescription substituem nome e descrição nas meta tags quando preenchidos
+	SEOTitle       string `json:"seoTitle"`
+	SEODescription string `json:"seoDescription"`
+
+	// ProductCount é calculado nas listagens de categorias e nunca gravado
+	ProductCount int64 `json:"productCount" gorm:"->;-:migration"`
+
+	// AttributeSchema define os campos extras que os produtos da categoria preenchem
+	AttributeSchema AttributeSchema `json:"attributeSchema" gorm:"type:jsonb;serializer:json"`
+
+	// Translations guarda nome e descrição em outros idiomas; os campos principais ficam em pt-BR
+	Translations Translations `json:"translations,omitempty" gorm:"type:jsonb;serializer:json"`
+
+	// Version é incrementada a cada gravação e exposta como ETag
+	Version int `json:"version" gorm:"default:1;not null"`
+}
+
+// CategoryBreadcrumb é um nível do caminho de uma categoria, da raiz até ela
+type CategoryBreadcrumb struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
 }
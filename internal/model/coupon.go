@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CouponType define como o valor do cupom é aplicado
+type CouponType string
+
+const (
+	CouponPercentage CouponType = "percentage" // Value é a porcentagem de desconto
+	CouponFixed      CouponType = "fixed"      // Value é um valor em reais
+)
+
+// IsValid indica se o tipo de cupom é conhecido
+func (t CouponType) IsValid() bool {
+	return t == CouponPercentage || t == CouponFixed
+}
+
+/**
+ * Coupon is a discount code such as "LARI10". Codes are stored in upper case
+ * and only need to be unique among coupons that are not deleted. Zero limits
+ * and a zero MinOrderValue mean no restriction; CategoryIDs limits the
+ * discount to items of those categories and their subcategories.
+ * RedemptionCount is only changed inside the redemption transaction.
+ * Indexes:
+ * - idx_coupon_code: Unique code among active coupons
+ */
+type Coupon struct {
+	gorm.Model
+	Code                  string     `json:"code" gorm:"size:40;not null;uniqueIndex:idx_coupon_code,where:deleted_at IS NULL"`
+	Description           string     `json:"description"`
+	Enabled               bool       `json:"enabled"`
+	Type                  CouponType `json:"type" gorm:"size:20;not null"`
+	Value                 float64    `json:"value" gorm:"not null;check:chk_coupons_value,value > 0"`
+	StartAt               *time.Time `json:"startAt"`
+	EndAt                 *time.Time `json:"endAt"`
+	MaxRedemptions        int        `json:"maxRedemptions" gorm:"default:0"`
+	MaxRedemptionsPerUser int        `json:"maxRedemptionsPerUser" gorm:"default:0"`
+	MinOrderValue         float64    `json:"minOrderValue" gorm:"default:0"`
+	CategoryIDs           []uint     `json:"categoryIds" gorm:"type:jsonb;serializer:json"`
+	RedemptionCount       int        `json:"redemptionCount" gorm:"default:0;not null"`
+}
+
+/**
+ * CouponRedemption records one use of a coupon by a user, with the amounts
+ * calculated when it was redeemed.
+ * Indexes:
+ * - idx_coupon_redemption_user: Counting uses per coupon and user
+ */
+type CouponRedemption struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	CouponID  uint      `json:"couponId" gorm:"not null;index:idx_coupon_redemption_user,priority:1"`
+	UserID    uint      `json:"userId" gorm:"not null;index:idx_coupon_redemption_user,priority:2"`
+	Subtotal  float64   `json:"subtotal"`
+	Discount  float64   `json:"discount"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// BaseCurrency é a moeda em que os preços são cadastrados
+const BaseCurrency = "BRL"
+
+// SupportedCurrencies lista as moedas aceitas em ?currency=; a primeira é a moeda base
+var SupportedCurrencies = []string{BaseCurrency, "USD", "EUR"}
+
+// CurrencySymbols é o símbolo usado no texto dos preços convertidos
+var CurrencySymbols = map[string]string{BaseCurrency: "R$", "USD": "US$", "EUR": "€"}
+
+// IsSupportedCurrency indica se a moeda está na lista de moedas aceitas
+func IsSupportedCurrency(currency string) bool {
+	return containsString(SupportedCurrencies, currency)
+}
+
+/**
+ * ExchangeRate is the value in BRL of one unit of a currency, valid from
+ * EffectiveDate until the next rate of the same currency. Rates are entered
+ * by the admin or imported from a file; there is at most one per day.
+ */
+type ExchangeRate struct {
+	Currency      string    `json:"currency" gorm:"primaryKey;size:3"`
+	EffectiveDate time.Time `json:"effectiveDate" gorm:"primaryKey;type:date"`
+	Rate          float64   `json:"rate" gorm:"not null;check:chk_exchange_rates_rate,rate > 0"`
+	Source        string    `json:"source"` // manual ou import
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+/**
+ * ConvertedPrice is a product price shown in another currency, next to the
+ * original BRL amounts. Min and Max come from the product's PriceRange; the
+ * Discounted fields convert the promotional price when there is one.
+ */
+type ConvertedPrice struct {
+	Currency         string    `json:"currency"`
+	Min              float64   `json:"min"`
+	Max              float64   `json:"max"`
+	Text             string    `json:"text"`
+	OriginalCurrency string    `json:"originalCurrency"`
+	OriginalMin      float64   `json:"originalMin"`
+	OriginalMax      float64   `json:"originalMax"`
+	OriginalText     string    `json:"originalText"`
+	Rate             float64   `json:"rate"`
+	RateDate         time.Time `json:"rateDate"`
+	DiscountedMin    float64   `json:"discountedMin,omitempty"`
+	DiscountedMax    float64   `json:"discountedMax,omitempty"`
+	DiscountedText   string    `json:"discountedText,omitempty"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+/**
+ * Favorite links a user to a product in their wishlist. Rows are kept when
+ * the product goes to the trash; listings simply skip products that are not
+ * visible, so favorites come back if the product is restored.
+ * Indexes:
+ * - idx_favorite_user_product: One favorite per user and product
+ * - idx_favorite_product: Counting favorites per product
+ */
+type Favorite struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UserID    uint      `json:"userId" gorm:"uniqueIndex:idx_favorite_user_product,priority:1"`
+	ProductID uint      `json:"productId" gorm:"uniqueIndex:idx_favorite_user_product,priority:2;index:idx_favorite_product"`
+}
+
+// FavoriteReportItem é uma linha do relatório de produtos mais favoritados
+type FavoriteReportItem struct {
+	ProductID uint   `json:"productId"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	Favorites int64  `json:"favorites" gorm:"column:favorite_count"`
+}
@@ -1,6 +1,49 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductStatus define o estado de publicação de um produto
+type ProductStatus string
+
+const (
+	ProductStatusDraft     ProductStatus = "draft"
+	ProductStatusPublished ProductStatus = "published"
+	ProductStatusArchived  ProductStatus = "archived"
+)
+
+// IsValid indica se o status é um dos valores conhecidos
+func (s ProductStatus) IsValid() bool {
+	switch s {
+	case ProductStatusDraft, ProductStatusPublished, ProductStatusArchived:
+		return true
+	}
+	return false
+}
+
+// ProductAvailability define como um produto pode ser adquirido
+type ProductAvailability string
+
+const (
+	// AvailabilityInStock indica peça pronta, limitada pelo estoque
+	AvailabilityInStock ProductAvailability = "in_stock"
+	// AvailabilityMadeToOrder indica peça feita sob encomenda, com prazo de produção
+	AvailabilityMadeToOrder ProductAvailability = "made_to_order"
+	// AvailabilityUnavailable indica que o produto não pode ser pedido no momento
+	AvailabilityUnavailable ProductAvailability = "unavailable"
+)
+
+// IsValid indica se a disponibilidade é um dos valores conhecidos
+func (a ProductAvailability) IsValid() bool {
+	switch a {
+	case AvailabilityInStock, AvailabilityMadeToOrder, AvailabilityUnavailable:
+		return true
+	}
+	return false
+}
 
 /**
  * Product represents a crochet product in the catalog.
@@ -8,13 +51,52 @@ import "gorm.io/gorm"
  * - idx_product_name: Optimizes name-based searches
  * - idx_product_category: Optimizes category filtering
  * - idx_product_category_name: Composite index for category + name sorting
+ * - idx_product_slug: Unique lookup by SEO-friendly slug
+ * - idx_product_status: Optimizes public visibility filtering
+ * - idx_product_featured: Optimizes the featured products listing
+ * - idx_product_availability: Optimizes the availability filter
+ * - idx_product_rating: Optimizes sorting by rating
+ *
+ * Only published products are shown on the storefront. PublishAt schedules a
+ * draft to go live and UnpublishAt schedules a published product to be archived.
+ * Position is the manual order of the product inside its category.
+ * StockQuantity only matters for in_stock products, which count as
+ * unavailable once it reaches zero; LeadTimeDays is the production time of
+ * made_to_order products. Attributes holds the values of the custom fields
+ * defined by the category's AttributeSchema. RatingAverage and RatingCount
+ * summarize approved reviews and are recalculated on every moderation.
+ * Translations holds the name and description in other locales; the own
+ * fields are the pt-BR text. Version is incremented on every save and
+ * exposed as the ETag, so two admins editing the same product cannot
+ * silently overwrite each other. Price is only filled when a response is
+ * asked in another currency, Discount when a running promotion targets the
+ * product and Breadcrumbs holds the category path on public responses; none
+ * of them is stored.
  */
 type Product struct {
 	gorm.Model
-	Name        string   `json:"name" gorm:"index:idx_product_name;index:idx_product_category_name,priority:2"`
-	Description string   `json:"description"`
-	ImageUrls   string   `json:"imageUrls"`
-	PriceRange  string   `json:"priceRange"`
-	CategoryID  uint     `json:"categoryId" gorm:"index:idx_product_category;index:idx_product_category_name,priority:1"`
-	Category    Category `json:"category"`
+	Name          string               `json:"name" gorm:"index:idx_product_name;index:idx_product_category_name,priority:2"`
+	Slug          string               `json:"slug" gorm:"uniqueIndex:idx_product_slug"`
+	Description   string               `json:"description"`
+	ImageUrls     string               `json:"imageUrls"`
+	PriceRange    string               `json:"priceRange"`
+	CategoryID    uint                 `json:"categoryId" gorm:"index:idx_product_category;index:idx_product_category_name,priority:1"`
+	Category      Category             `json:"category"`
+	Status        ProductStatus        `json:"status" gorm:"default:published;index:idx_product_status"`
+	PublishAt     *time.Time           `json:"publishAt"`
+	UnpublishAt   *time.Time           `json:"unpublishAt"`
+	Featured      bool                 `json:"featured" gorm:"default:false;index:idx_product_featured"`
+	Position      int                  `json:"position" gorm:"default:0"`
+	Tags          []Tag                `json:"tags" gorm:"many2many:product_tags;"`
+	Availability  ProductAvailability  `json:"availability" gorm:"default:made_to_order;index:idx_product_availability"`
+	StockQuantity int                  `json:"stockQuantity" gorm:"default:0;check:chk_products_stock_quantity,stock_quantity >= 0"`
+	LeadTimeDays  int                  `json:"leadTimeDays" gorm:"default:0"`
+	Attributes    ProductAttributes    `json:"attributes" gorm:"type:jsonb;serializer:json"`
+	RatingAverage float64              `json:"ratingAverage" gorm:"default:0;index:idx_product_rating,priority:1"`
+	RatingCount   int                  `json:"ratingCount" gorm:"default:0;index:idx_product_rating,priority:2"`
+	Translations  Translations         `json:"translations,omitempty" gorm:"type:jsonb;serializer:json"`
+	Version       int                  `json:"version" gorm:"default:1;not null"`
+	Price         *ConvertedPrice      `json:"price,omitempty" gorm:"-"`
+	Discount      *ProductDiscount     `json:"discount,omitempty" gorm:"-"`
+	Breadcrumbs   []CategoryBreadcrumb `json:"breadcrumbs,omitempty" gorm:"-"`
 }
@@ -0,0 +1,67 @@
+package model
+
+import "fmt"
+
+// ProductSort define a ordenação das listagens de produtos
+type ProductSort string
+
+const (
+	// SortName ordena alfabeticamente pelo nome
+	SortName ProductSort = "name"
+	// SortNewest ordena dos mais recentes para os mais antigos
+	SortNewest ProductSort = "newest"
+	// SortManual segue a ordem definida pelo admin (destaques primeiro, depois a posição na categoria)
+	SortManual ProductSort = "manual"
+	// SortRating ordena pela média das avaliações aprovadas (e depois pela quantidade)
+	SortRating ProductSort = "rating"
+	// SortPopular ordena pelas visualizações dos últimos dias
+	SortPopular ProductSort = "popular"
+)
+
+// PopularityWindowDays é a janela de visualizações considerada pela ordenação popular
+const PopularityWindowDays = 30
+
+// ParseProductSort converte o parâmetro de ordenação, usando fallback para valores vazios ou desconhecidos
+func ParseProductSort(value string, fallback ProductSort) ProductSort {
+	switch ProductSort(value) {
+	case SortName, SortNewest, SortManual, SortRating, SortPopular:
+		return ProductSort(value)
+	}
+	return fallback
+}
+
+// ProductFilter reúne os filtros e a ordenação das listagens públicas de produtos
+type ProductFilter struct {
+	Sort               ProductSort
+	Tag                string              // slug da tag
+	Availability       ProductAvailability // vazio não filtra
+	Attributes         []AttributeFilter   // ordenados pela chave
+	Locale             string              // idioma dos textos da resposta
+	IncludeDescendants bool                // inclui os produtos das subcategorias na listagem por categoria
+}
+
+// AttributeFilter filtra produtos pelo valor de um atributo: igualdade (Value) ou faixa numérica (Min/Max)
+type AttributeFilter struct {
+	Key   string
+	Value string
+	Min   *float64
+	Max   *float64
+}
+
+// CacheKey gera um sufixo de chave de cache que identifica o filtro
+func (f ProductFilter) CacheKey() string {
+	key := f.Locale + ":" + string(f.Sort) + ":" + f.Tag + ":" + string(f.Availability)
+	if f.IncludeDescendants {
+		key += ":tree"
+	}
+	for _, a := range f.Attributes {
+		key += ":" + a.Key + "=" + a.Value
+		if a.Min != nil {
+			key += fmt.Sprintf(">%g", *a.Min)
+		}
+		if a.Max != nil {
+			key += fmt.Sprintf("<%g", *a.Max)
+		}
+	}
+	return key
+}
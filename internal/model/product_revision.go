@@ -0,0 +1,103 @@
+package model
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+/**
+ * ProductSnapshot holds the editable content of a product at a point in time.
+ * Stock is left out because sales change it without a revision. Revisions
+ * saved before attributes, translations, tags and availability were tracked
+ * have them empty (null or ""); Diff skips them and a restore keeps the
+ * current values.
+ */
+type ProductSnapshot struct {
+	Name         string              `json:"name"`
+	Description  string              `json:"description"`
+	ImageUrls    string              `json:"imageUrls"`
+	PriceRange   string              `json:"priceRange"`
+	CategoryID   uint                `json:"categoryId"`
+	Attributes   ProductAttributes   `json:"attributes"`
+	Translations Translations        `json:"translations"`
+	Tags         []string            `json:"tags"`
+	Availability ProductAvailability `json:"availability"`
+	LeadTimeDays int                 `json:"leadTimeDays"`
+}
+
+/**
+ * ProductRevision stores the content a product had right before an update,
+ * so any earlier version can be compared or restored.
+ * Indexes:
+ * - idx_product_revision: Unique revision number per product
+ */
+type ProductRevision struct {
+	ID        uint            `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time       `json:"createdAt"`
+	ProductID uint            `json:"productId" gorm:"uniqueIndex:idx_product_revision,priority:1"`
+	Revision  int             `json:"revision" gorm:"uniqueIndex:idx_product_revision,priority:2"`
+	Snapshot  ProductSnapshot `json:"snapshot" gorm:"type:jsonb;serializer:json"`
+}
+
+// FieldChange descreve a alteração de um campo entre duas versões
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// SnapshotOf extrai o conteúdo editável de um produto; as tags vêm de p.Tags, que precisa estar carregado
+func SnapshotOf(p *Product) ProductSnapshot {
+	snapshot := ProductSnapshot{
+		Name:         p.Name,
+		Description:  p.Description,
+		ImageUrls:    p.ImageUrls,
+		PriceRange:   p.PriceRange,
+		CategoryID:   p.CategoryID,
+		Attributes:   ProductAttributes{},
+		Translations: Translations{},
+		Tags:         TagNames(p.Tags),
+		Availability: p.Availability,
+		LeadTimeDays: p.LeadTimeDays,
+	}
+	for key, value := range p.Attributes {
+		snapshot.Attributes[key] = value
+	}
+	for locale, texts := range p.Translations {
+		snapshot.Translations[locale] = texts
+	}
+	sort.Strings(snapshot.Tags)
+	return snapshot
+}
+
+// Diff lista os campos que mudaram de s para next, ignorando os que uma das versões não registrou
+func (s ProductSnapshot) Diff(next ProductSnapshot) []FieldChange {
+	changes := []FieldChange{}
+	add := func(field string, from, to interface{}) {
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+
+	add("name", s.Name, next.Name)
+	add("description", s.Description, next.Description)
+	add("imageUrls", s.ImageUrls, next.ImageUrls)
+	add("priceRange", s.PriceRange, next.PriceRange)
+	add("categoryId", s.CategoryID, next.CategoryID)
+	if s.Attributes != nil && next.Attributes != nil {
+		add("attributes", s.Attributes, next.Attributes)
+	}
+	if s.Translations != nil && next.Translations != nil {
+		add("translations", s.Translations, next.Translations)
+	}
+	if s.Tags != nil && next.Tags != nil {
+		add("tags", s.Tags, next.Tags)
+	}
+	if s.Availability != "" && next.Availability != "" {
+		add("availability", s.Availability, next.Availability)
+		add("leadTimeDays", s.LeadTimeDays, next.LeadTimeDays)
+	}
+
+	return changes
+}
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// ViewKind identifica a origem de uma visualização de produto
+type ViewKind string
+
+const (
+	// ViewKindDetail é a abertura da página do produto
+	ViewKindDetail ViewKind = "detail"
+	// ViewKindImage é a consulta da galeria de imagens do produto
+	ViewKindImage ViewKind = "image"
+)
+
+/**
+ * ProductViewDaily holds the number of deduplicated views a product got on
+ * a given day (UTC). Rows are upserted in batches by the view tracker.
+ */
+type ProductViewDaily struct {
+	ProductID   uint      `json:"productId" gorm:"primaryKey;autoIncrement:false"`
+	Day         time.Time `json:"day" gorm:"primaryKey;type:date"`
+	DetailViews int64     `json:"detailViews" gorm:"default:0"`
+	ImageViews  int64     `json:"imageViews" gorm:"default:0"`
+}
+
+/**
+ * ProductCoView counts how often visitors looked at two products in the same
+ * session. Each pair is stored in both directions so lookups by product stay
+ * simple.
+ */
+type ProductCoView struct {
+	ProductID        uint      `json:"productId" gorm:"primaryKey;autoIncrement:false"`
+	RelatedProductID uint      `json:"relatedProductId" gorm:"primaryKey;autoIncrement:false"`
+	Count            int64     `json:"count" gorm:"default:0"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// ViewReportProduct é uma linha do relatório de visualizações por produto
+type ViewReportProduct struct {
+	ProductID   uint   `json:"productId"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	DetailViews int64  `json:"detailViews"`
+	ImageViews  int64  `json:"imageViews"`
+	TotalViews  int64  `json:"totalViews"`
+}
+
+// ViewReportDay é o total de visualizações de um dia
+type ViewReportDay struct {
+	Day         time.Time `json:"day"`
+	DetailViews int64     `json:"detailViews"`
+	ImageViews  int64     `json:"imageViews"`
+}
@@ -11,9 +11,52 @@ type ProgressiveRule struct {
 	Percentage int `json:"percentage"`
 }
 
+// PromotionStatus é a situação de uma promoção em relação ao horário atual
+type PromotionStatus string
+
+const (
+	PromotionStatusDisabled  PromotionStatus = "disabled"  // desligada pelo admin
+	PromotionStatusScheduled PromotionStatus = "scheduled" // ligada, mas a janela ainda não começou
+	PromotionStatusRunning   PromotionStatus = "running"   // dentro da janela, mas outra de maior prioridade vence
+	PromotionStatusActive    PromotionStatus = "active"    // é a promoção exibida agora
+	PromotionStatusExpired   PromotionStatus = "expired"   // a janela já terminou
+)
+
+// PromotionTargets seleciona produtos por categoria (inclusive subcategorias), por ID ou por slug de tag
+type PromotionTargets struct {
+	CategoryIDs []uint   `json:"categoryIds,omitempty"`
+	ProductIDs  []uint   `json:"productIds,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// IsEmpty indica se nenhum critério foi informado
+func (t PromotionTargets) IsEmpty() bool {
+	return len(t.CategoryIDs) == 0 && len(t.ProductIDs) == 0 && len(t.Tags) == 0
+}
+
+/**
+ * ProductDiscount is the GlobalPercentage of the promotion that applies to a
+ * product, with the discounted price in the base currency. It is computed on
+ * every public response and never stored.
+ */
+type ProductDiscount struct {
+	PromotionID   uint       `json:"promotionId"`
+	PromotionName string     `json:"promotionName"`
+	Percentage    int        `json:"percentage"`
+	Min           float64    `json:"min"`
+	Max           float64    `json:"max"`
+	Text          string     `json:"text"` // priceRange com os valores já descontados
+	EndAt         *time.Time `json:"endAt,omitempty"`
+}
+
 type Promotion struct {
 	gorm.Model
+	Name                     string            `json:"name"`                               // nome interno, ex.: Black Friday
+	Priority                 int               `json:"priority" gorm:"default:0;not null"` // entre promoções ativas ao mesmo tempo, a maior vence
+	Status                   PromotionStatus   `json:"status,omitempty" gorm:"-"`          // calculado nas respostas do admin, nunca gravado
 	Enabled                  bool              `json:"enabled"`
+	Targets                  PromotionTargets  `json:"targets" gorm:"type:jsonb;serializer:json"`  // vazio: vale para a loja toda
+	Excludes                 PromotionTargets  `json:"excludes" gorm:"type:jsonb;serializer:json"` // sempre ficam de fora, mesmo se alvo
 	GlobalPercentage         *int              `json:"globalPercentage"`
 	ProgressiveRules         []ProgressiveRule `json:"progressiveRules" gorm:"type:jsonb;serializer:json"`
 	StartAt                  *time.Time        `json:"startAt"`
@@ -40,4 +83,35 @@ type Promotion struct {
 	BannerCountdownBgColor   *string           `json:"bannerCountdownBgColor"`
 	BannerCountdownTextColor *string           `json:"bannerCountdownTextColor"`
 	BannerCountdownSize      *string           `json:"bannerCountdownSize"`
+	Translations             Translations      `json:"translations,omitempty" gorm:"type:jsonb;serializer:json"` // messageTemplate e bannerTitle em outros idiomas
+}
+
+// InWindow indica se a promoção está ligada e dentro da sua janela no instante informado
+func (p *Promotion) InWindow(at time.Time) bool {
+	if !p.Enabled {
+		return false
+	}
+	if p.StartAt != nil && at.Before(*p.StartAt) {
+		return false
+	}
+	if p.EndAt != nil && at.After(*p.EndAt) {
+		return false
+	}
+	return true
+}
+
+// StatusAt calcula a situação da promoção no instante informado; current indica se ela é a vencedora
+func (p *Promotion) StatusAt(at time.Time, current bool) PromotionStatus {
+	switch {
+	case !p.Enabled:
+		return PromotionStatusDisabled
+	case p.StartAt != nil && at.Before(*p.StartAt):
+		return PromotionStatusScheduled
+	case p.EndAt != nil && at.After(*p.EndAt):
+		return PromotionStatusExpired
+	case current:
+		return PromotionStatusActive
+	default:
+		return PromotionStatusRunning
+	}
 }
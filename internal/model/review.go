@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReviewStatus define o estado de moderação de uma avaliação
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "pending"
+	ReviewStatusApproved ReviewStatus = "approved"
+	ReviewStatusRejected ReviewStatus = "rejected"
+)
+
+// IsValid indica se o status é um dos valores conhecidos
+func (s ReviewStatus) IsValid() bool {
+	switch s {
+	case ReviewStatusPending, ReviewStatusApproved, ReviewStatusRejected:
+		return true
+	}
+	return false
+}
+
+/**
+ * Review is a customer's rating and comment on a product. Reviews start as
+ * pending and only approved ones are public and count towards the product's
+ * rating. AuthorName keeps the name shown at the time the review was posted.
+ * Indexes:
+ * - idx_review_product_status: Approved reviews of a product
+ * - idx_review_user: Reviews written by a user
+ * - idx_review_status: The moderation queue
+ */
+type Review struct {
+	gorm.Model
+	ProductID   uint         `json:"productId" gorm:"index:idx_review_product_status,priority:1"`
+	UserID      uint         `json:"userId" gorm:"index:idx_review_user"`
+	AuthorName  string       `json:"authorName"`
+	Rating      int          `json:"rating" gorm:"check:chk_reviews_rating,rating BETWEEN 1 AND 5"`
+	Text        string       `json:"text"`
+	PhotoUrls   string       `json:"photoUrls"`
+	Status      ReviewStatus `json:"status" gorm:"default:pending;index:idx_review_product_status,priority:2;index:idx_review_status"`
+	Reply       string       `json:"reply"`
+	RepliedAt   *time.Time   `json:"repliedAt"`
+	ModeratedAt *time.Time   `json:"moderatedAt"`
+}
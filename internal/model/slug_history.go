@@ -0,0 +1,21 @@
+package model
+
+import "gorm.io/gorm"
+
+const (
+	SlugEntityProduct  = "product"
+	SlugEntityCategory = "category"
+)
+
+/**
+ * SlugHistory keeps slugs that an entity used before being renamed,
+ * so old links can be redirected to the current slug.
+ * Indexes:
+ * - idx_slug_history_entity_slug: Unique lookup by entity type + old slug
+ */
+type SlugHistory struct {
+	gorm.Model
+	EntityType string `json:"entityType" gorm:"uniqueIndex:idx_slug_history_entity_slug,priority:1"`
+	EntityID   uint   `json:"entityId" gorm:"index"`
+	Slug       string `json:"slug" gorm:"uniqueIndex:idx_slug_history_entity_slug,priority:2"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+/**
+ * Tag groups products across categories (e.g. "presente", "bebê", "chaveiro").
+ * Products and tags are linked through the product_tags join table.
+ * Indexes:
+ * - idx_tag_slug: Unique lookup by slug
+ */
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex:idx_tag_slug"`
+}
+
+// TagWithCount é uma tag acompanhada da quantidade de produtos visíveis que a utilizam
+type TagWithCount struct {
+	Tag
+	ProductCount int64 `json:"productCount"`
+}
+
+// TagNames retorna os nomes das tags na ordem recebida
+func TagNames(tags []Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
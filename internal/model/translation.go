@@ -0,0 +1,155 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale é o idioma dos campos principais (Name, Description, ...) e o fallback das traduções
+const DefaultLocale = "pt-BR"
+
+// SupportedLocales lista os idiomas aceitos pela API; o primeiro é o padrão
+var SupportedLocales = []string{DefaultLocale, "en", "ja"}
+
+// Campos traduzíveis de cada entidade, com os nomes usados no JSON
+var (
+	ProductTranslatableFields   = []string{"name", "description"}
+	CategoryTranslatableFields  = []string{"name", "description", "seoTitle", "seoDescription"}
+	PromotionTranslatableFields = []string{"messageTemplate", "bannerTitle"}
+)
+
+/**
+ * Translations holds the translated texts of a record by locale and field,
+ * e.g. {"en": {"name": "Bear amigurumi"}, "ja": {"name": "クマのあみぐるみ"}}.
+ * The default locale is never stored here: it lives in the record's own fields.
+ */
+type Translations map[string]map[string]string
+
+// IsSupportedLocale indica se o idioma está na lista de idiomas aceitos
+func IsSupportedLocale(locale string) bool {
+	return containsString(SupportedLocales, locale)
+}
+
+// Text retorna o texto traduzido do campo, ou o fallback quando não há tradução
+func (t Translations) Text(locale, field, fallback string) string {
+	if text := t[locale][field]; text != "" {
+		return text
+	}
+	return fallback
+}
+
+/**
+ * Normalize checks that every locale is supported (and not the default one)
+ * and every field can be translated, trims the texts and drops empty ones.
+ *
+ * @param fields - The translatable fields of the entity
+ */
+func (t Translations) Normalize(fields []string) (Translations, error) {
+	normalized := Translations{}
+	for locale, texts := range t {
+		if locale == DefaultLocale {
+			return nil, fmt.Errorf("traduções para %s ficam nos campos principais", DefaultLocale)
+		}
+		if !IsSupportedLocale(locale) {
+			return nil, fmt.Errorf("idioma %q não suportado (use %s)", locale, strings.Join(SupportedLocales[1:], ", "))
+		}
+		for field, text := range texts {
+			if !containsString(fields, field) {
+				return nil, fmt.Errorf("campo %q não pode ser traduzido (use %s)", field, strings.Join(fields, ", "))
+			}
+			if text = strings.TrimSpace(text); text != "" {
+				if normalized[locale] == nil {
+					normalized[locale] = map[string]string{}
+				}
+				normalized[locale][field] = text
+			}
+		}
+	}
+	return normalized, nil
+}
+
+/**
+ * Missing lists, per locale, the fields that have text in the default locale
+ * but no translation yet.
+ *
+ * @param locales - The locales to check
+ * @param base - The default-locale text of each translatable field
+ */
+func (t Translations) Missing(locales []string, base map[string]string) map[string][]string {
+	missing := map[string][]string{}
+	for _, locale := range locales {
+		for field, text := range base {
+			if strings.TrimSpace(text) != "" && t[locale][field] == "" {
+				missing[locale] = append(missing[locale], field)
+			}
+		}
+		sort.Strings(missing[locale])
+	}
+	for locale, fields := range missing {
+		if len(fields) == 0 {
+			delete(missing, locale)
+		}
+	}
+	return missing
+}
+
+// TranslatableTexts retorna os textos do produto no idioma padrão, por campo traduzível
+func (p *Product) TranslatableTexts() map[string]string {
+	return map[string]string{"name": p.Name, "description": p.Description}
+}
+
+// Localize troca os textos do produto (e da sua categoria) pelos do idioma pedido e omite as traduções da resposta
+func (p *Product) Localize(locale string) {
+	p.Name = p.Translations.Text(locale, "name", p.Name)
+	p.Description = p.Translations.Text(locale, "description", p.Description)
+	p.Translations = nil
+	p.Category.Localize(locale)
+}
+
+// TranslatableTexts retorna os textos da categoria no idioma padrão, por campo traduzível
+func (c *Category) TranslatableTexts() map[string]string {
+	return map[string]string{
+		"name":           c.Name,
+		"description":    c.Description,
+		"seoTitle":       c.SEOTitle,
+		"seoDescription": c.SEODescription,
+	}
+}
+
+// Localize troca os textos da categoria pelos do idioma pedido e omite as traduções da resposta
+func (c *Category) Localize(locale string) {
+	c.Name = c.Translations.Text(locale, "name", c.Name)
+	c.Description = c.Translations.Text(locale, "description", c.Description)
+	c.SEOTitle = c.Translations.Text(locale, "seoTitle", c.SEOTitle)
+	c.SEODescription = c.Translations.Text(locale, "seoDescription", c.SEODescription)
+	c.Translations = nil
+	for i := range c.Products {
+		c.Products[i].Localize(locale)
+	}
+}
+
+// TranslatableTexts retorna os textos da promoção no idioma padrão, por campo traduzível
+func (p *Promotion) TranslatableTexts() map[string]string {
+	texts := map[string]string{}
+	if p.MessageTemplate != nil {
+		texts["messageTemplate"] = *p.MessageTemplate
+	}
+	if p.BannerTitle != nil {
+		texts["bannerTitle"] = *p.BannerTitle
+	}
+	return texts
+}
+
+// Localize troca os textos da promoção pelos do idioma pedido e omite as traduções da resposta
+func (p *Promotion) Localize(locale string) {
+	if p.MessageTemplate != nil {
+		text := p.Translations.Text(locale, "messageTemplate", *p.MessageTemplate)
+		p.MessageTemplate = &text
+	}
+	if p.BannerTitle != nil {
+		text := p.Translations.Text(locale, "bannerTitle", *p.BannerTitle)
+		p.BannerTitle = &text
+	}
+	p.Translations = nil
+}
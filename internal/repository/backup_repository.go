@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+)
+
+// CatalogData agrupa os registros do catálogo usados em backup e restauração
+type CatalogData struct {
+	Categories []model.Category
+	Products   []model.Product
+	Promotions []model.Promotion
+}
+
+// CatalogRestoreResult conta o que foi criado e atualizado na restauração, além das promoções e cupons desativados por perderem os alvos
+type CatalogRestoreResult struct {
+	CategoriesCreated  int `json:"categoriesCreated"`
+	CategoriesUpdated  int `json:"categoriesUpdated"`
+	ProductsCreated    int `json:"productsCreated"`
+	ProductsUpdated    int `json:"productsUpdated"`
+	Promotions         int `json:"promotions"`
+	PromotionsDisabled int `json:"promotionsDisabled"`
+	CouponsDisabled    int `json:"couponsDisabled"`
+}
+
+// GetPromotions retorna todas as promoções cadastradas
+func GetPromotions() ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	if err := config.DB.Order("id ASC").Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+/**
+ * RestoreCatalog writes a backup into the database in a single transaction.
+ * IDs from the archive are never reused: every record gets a new ID (or the ID
+ * of the record it is merged into) and product category references are remapped.
+ *
+ * In replace mode the current catalog (including the trash, revisions and slug
+ * history) is removed first. Reviews, favorites and view statistics are moved
+ * to the restored product with the same slug and dropped when there is none.
+ * Coupon redemptions only reference coupons and users, which are not part of
+ * the catalog, so they are kept as they are. In merge mode categories and
+ * products are matched by slug, including soft-deleted ones, which are
+ * brought back, and promotions by name (or, when unnamed, by creation time).
+ * Product ratings are recalculated from the reviews in both modes.
+ *
+ * Promotion targets and excludes are remapped to the new category and product
+ * IDs; references to records missing from the backup are dropped. A promotion
+ * left without any target would apply to the whole store, so it is disabled.
+ * Coupons are not part of the backup, but after a replace their categories
+ * are remapped by slug in the same way.
+ *
+ * @param data - Records read from the archive, with their original IDs
+ * @param replace - Whether to wipe the current catalog before restoring
+ */
+func RestoreCatalog(data CatalogData, replace bool) (*CatalogRestoreResult, error) {
+	result := &CatalogRestoreResult{}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// IDs atuais por slug, para religar avaliações, favoritos e visualizações aos produtos restaurados
+		var previousProducts []model.Product
+		var previousCategories []model.Category
+		if replace {
+			if err := tx.Unscoped().Select("id", "slug").Find(&previousProducts).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Select("id", "slug").Find(&previousCategories).Error; err != nil {
+				return err
+			}
+			wipe := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped()
+			if err := tx.Exec("DELETE FROM product_tags").Error; err != nil {
+				return err
+			}
+			for _, table := range []interface{}{
+				&model.ProductRevision{}, &model.SlugHistory{}, &model.Product{}, &model.Category{}, &model.Promotion{}, &model.Tag{},
+			} {
+				if err := wipe.Delete(table).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		categoryIDs := make(map[uint]uint, len(data.Categories))
+		categoryIDsBySlug := make(map[string]uint, len(data.Categories))
+		oldParents := map[uint]uint{}
+		for _, category := range data.Categories {
+			oldID := category.ID
+			category.ID = 0
+			category.DeletedAt = gorm.DeletedAt{}
+			category.Products = nil
+			// A categoria mãe é ligada depois que todas tiverem o novo ID
+			if category.ParentID != nil {
+				oldParents[oldID] = *category.ParentID
+				category.ParentID = nil
+			}
+
+			if !replace {
+				var existing model.Category
+				err := tx.Unscoped().Where("slug = ?", category.Slug).Limit(1).Find(&existing).Error
+				if err != nil {
+					return err
+				}
+				category.ID = existing.ID
+				category.Version = existing.Version + 1
+			}
+
+			if category.ID != 0 {
+				if err := tx.Unscoped().Save(&category).Error; err != nil {
+					return err
+				}
+				result.CategoriesUpdated++
+			} else {
+				if err := tx.Create(&category).Error; err != nil {
+					return err
+				}
+				result.CategoriesCreated++
+			}
+			categoryIDs[oldID] = category.ID
+			categoryIDsBySlug[category.Slug] = category.ID
+		}
+		for oldID, oldParentID := range oldParents {
+			parentID, ok := categoryIDs[oldParentID]
+			if !ok {
+				continue
+			}
+			if err := tx.Unscoped().Model(&model.Category{}).Where("id = ?", categoryIDs[oldID]).
+				UpdateColumn("parent_id", parentID).Error; err != nil {
+				return err
+			}
+		}
+
+		if replace {
+			remapped := make(map[uint]uint, len(previousCategories))
+			for _, previous := range previousCategories {
+				if newID, ok := categoryIDsBySlug[previous.Slug]; ok {
+					remapped[previous.ID] = newID
+				}
+			}
+			disabled, err := remapCouponCategories(tx, remapped)
+			if err != nil {
+				return err
+			}
+			result.CouponsDisabled = disabled
+		}
+
+		productIDs := make(map[uint]uint, len(data.Products))
+		productIDsBySlug := make(map[string]uint, len(data.Products))
+		for _, product := range data.Products {
+			newCategoryID, ok := categoryIDs[product.CategoryID]
+			if !ok {
+				return fmt.Errorf("produto %q referencia a categoria %d, ausente no backup", product.Name, product.CategoryID)
+			}
+			tags := product.Tags
+			oldID := product.ID
+			product.ID = 0
+			product.CategoryID = newCategoryID
+			product.Category = model.Category{}
+			product.Tags = nil
+			product.DeletedAt = gorm.DeletedAt{}
+
+			if !replace {
+				var existing model.Product
+				err := tx.Unscoped().Where("slug = ?", product.Slug).Limit(1).Find(&existing).Error
+				if err != nil {
+					return err
+				}
+				product.ID = existing.ID
+				product.Version = existing.Version + 1
+			}
+
+			if product.ID != 0 {
+				if err := tx.Unscoped().Omit("Category", "Tags").Save(&product).Error; err != nil {
+					return err
+				}
+				result.ProductsUpdated++
+			} else {
+				if err := tx.Omit("Category", "Tags").Create(&product).Error; err != nil {
+					return err
+				}
+				result.ProductsCreated++
+			}
+
+			if err := restoreProductTags(tx, &product, tags); err != nil {
+				return err
+			}
+			productIDs[oldID] = product.ID
+			productIDsBySlug[product.Slug] = product.ID
+		}
+
+		if replace {
+			remapped := make(map[uint]uint, len(previousProducts))
+			for _, previous := range previousProducts {
+				if newID, ok := productIDsBySlug[previous.Slug]; ok {
+					remapped[previous.ID] = newID
+				}
+			}
+			if err := remapProductReferences(tx, remapped); err != nil {
+				return err
+			}
+		}
+		for _, productID := range productIDsBySlug {
+			if err := refreshProductRating(tx, productID); err != nil {
+				return err
+			}
+		}
+
+		for _, promotion := range data.Promotions {
+			promotion.ID = 0
+			promotion.DeletedAt = gorm.DeletedAt{}
+
+			targeted := !promotion.Targets.IsEmpty()
+			promotion.Targets = remapPromotionTargets(promotion.Targets, categoryIDs, productIDs)
+			promotion.Excludes = remapPromotionTargets(promotion.Excludes, categoryIDs, productIDs)
+			if targeted && promotion.Targets.IsEmpty() && promotion.Enabled {
+				promotion.Enabled = false
+				result.PromotionsDisabled++
+			}
+
+			if !replace {
+				var existing model.Promotion
+				query := tx.Unscoped()
+				if promotion.Name != "" {
+					query = query.Where("name = ?", promotion.Name)
+				} else {
+					query = query.Where("COALESCE(name, '') = '' AND created_at = ?", promotion.CreatedAt)
+				}
+				if err := query.Order("id ASC").Limit(1).Find(&existing).Error; err != nil {
+					return err
+				}
+				promotion.ID = existing.ID
+			}
+
+			if promotion.ID != 0 {
+				if err := tx.Unscoped().Save(&promotion).Error; err != nil {
+					return err
+				}
+			} else if err := tx.Create(&promotion).Error; err != nil {
+				return err
+			}
+			result.Promotions++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// remapPromotionTargets troca os IDs do backup pelos novos, descartando os que não foram restaurados
+func remapPromotionTargets(targets model.PromotionTargets, categoryIDs, productIDs map[uint]uint) model.PromotionTargets {
+	remap := func(ids []uint, newIDs map[uint]uint) []uint {
+		if ids == nil {
+			return nil
+		}
+		remapped := make([]uint, 0, len(ids))
+		for _, id := range ids {
+			if newID, ok := newIDs[id]; ok {
+				remapped = append(remapped, newID)
+			}
+		}
+		return remapped
+	}
+	targets.CategoryIDs = remap(targets.CategoryIDs, categoryIDs)
+	targets.ProductIDs = remap(targets.ProductIDs, productIDs)
+	return targets
+}
+
+/**
+ * remapCouponCategories points the categories of every coupon to their new
+ * IDs after a replace. A coupon whose categories all disappeared would become
+ * valid for any item, so it is disabled instead.
+ *
+ * @param categoryIDs - New category ID by the ID it had before the replace
+ * @returns - Number of coupons disabled
+ */
+func remapCouponCategories(tx *gorm.DB, categoryIDs map[uint]uint) (int, error) {
+	var coupons []model.Coupon
+	if err := tx.Unscoped().Find(&coupons).Error; err != nil {
+		return 0, err
+	}
+
+	disabled := 0
+	for _, coupon := range coupons {
+		if len(coupon.CategoryIDs) == 0 {
+			continue
+		}
+		remapped := make([]uint, 0, len(coupon.CategoryIDs))
+		for _, id := range coupon.CategoryIDs {
+			if newID, ok := categoryIDs[id]; ok {
+				remapped = append(remapped, newID)
+			}
+		}
+		coupon.CategoryIDs = remapped
+		if len(remapped) == 0 && coupon.Enabled {
+			coupon.Enabled = false
+			disabled++
+		}
+		if err := tx.Unscoped().Model(&coupon).Select("category_ids", "enabled").Updates(&coupon).Error; err != nil {
+			return 0, err
+		}
+	}
+	return disabled, nil
+}
+
+/**
+ * remapProductReferences points the rows that reference products to their new
+ * IDs after a replace. Each column is rewritten by a single UPDATE, so an ID
+ * is never mapped twice; rows whose product no longer exists are removed.
+ *
+ * @param productIDs - New product ID by the ID it had before the replace
+ */
+func remapProductReferences(tx *gorm.DB, productIDs map[uint]uint) error {
+	references := []struct{ table, column string }{
+		{"reviews", "product_id"},
+		{"favorites", "product_id"},
+		{"product_view_dailies", "product_id"},
+		{"product_co_views", "product_id"},
+		{"product_co_views", "related_product_id"},
+	}
+
+	if len(productIDs) > 0 {
+		values := make([]string, 0, len(productIDs))
+		args := make([]interface{}, 0, 2*len(productIDs))
+		for oldID, newID := range productIDs {
+			values = append(values, "(?::bigint, ?::bigint)")
+			args = append(args, oldID, newID)
+		}
+		for _, ref := range references {
+			sql := fmt.Sprintf("UPDATE %[1]s SET %[2]s = m.new_id FROM (VALUES %[3]s) AS m(old_id, new_id) WHERE %[1]s.%[2]s = m.old_id",
+				ref.table, ref.column, strings.Join(values, ", "))
+			if err := tx.Exec(sql, args...).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ref := range references {
+		sql := fmt.Sprintf("DELETE FROM %s WHERE %s NOT IN (SELECT id FROM products)", ref.table, ref.column)
+		if err := tx.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreProductTags vincula o produto às tags do backup, reaproveitando as existentes pelo slug
+func restoreProductTags(tx *gorm.DB, product *model.Product, tags []model.Tag) error {
+	resolved := make([]model.Tag, 0, len(tags))
+	for _, tag := range tags {
+		var existing model.Tag
+		if err := tx.Where(model.Tag{Slug: tag.Slug}).Attrs(model.Tag{Name: tag.Name}).FirstOrCreate(&existing).Error; err != nil {
+			return err
+		}
+		resolved = append(resolved, existing)
+	}
+	return tx.Model(product).Association("Tags").Replace(resolved)
+}
@@ -1,11 +1,19 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/jpeccia/lariharumi_croche_backend_go/config"
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrCategoryInUse indica que ainda há produtos fora da lixeira usando a categoria
+var ErrCategoryInUse = errors.New("a categoria ainda possui produtos")
+
 // CreateCategory cria uma nova categoria no banco de dados
 func CreateCategory(category *model.Category) error {
 	if err := config.DB.Create(category).Error; err != nil {
@@ -15,17 +23,106 @@ func CreateCategory(category *model.Category) error {
 }
 
 /**
- * GetCategories returns all categories without eager loading products.
+ * GetCategories returns all categories, hidden ones included, in display
+ * order (position, then name) without eager loading products.
  * Products should be fetched separately when needed.
  */
 func GetCategories() ([]model.Category, error) {
 	var categories []model.Category
-	if err := config.DB.Find(&categories).Error; err != nil {
+	if err := config.DB.Order("position ASC").Order("LOWER(name) ASC").Find(&categories).Error; err != nil {
 		return nil, err
 	}
 	return categories, nil
 }
 
+/**
+ * GetCategoriesWithProductCount returns all categories in display order with
+ * ProductCount filled by a single grouped subquery, instead of loading the
+ * products of each category.
+ *
+ * @param visibleOnly - Count only products shown on the storefront; otherwise every product not in the trash
+ */
+func GetCategoriesWithProductCount(visibleOnly bool) ([]model.Category, error) {
+	counts := config.DB.Model(&model.Product{}).
+		Select("products.category_id, COUNT(*) AS count").
+		Group("products.category_id")
+	if visibleOnly {
+		counts = counts.Scopes(visibleProducts)
+	}
+
+	var categories []model.Category
+	err := config.DB.Model(&model.Category{}).
+		Select("categories.*, COALESCE(pc.count, 0) AS product_count").
+		Joins("LEFT JOIN (?) AS pc ON pc.category_id = categories.id", counts).
+		Order("categories.position ASC").Order("LOWER(categories.name) ASC").
+		Find(&categories).Error
+	return categories, err
+}
+
+// siblingCategories filtra as categorias com a mesma mãe (nil para as de primeiro nível)
+func siblingCategories(parentID *uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if parentID == nil {
+			return db.Where("parent_id IS NULL")
+		}
+		return db.Where("parent_id = ?", *parentID)
+	}
+}
+
+// NextCategoryPosition retorna a posição logo após a última categoria irmã
+func NextCategoryPosition(parentID *uint) (int, error) {
+	var max int
+	err := config.DB.Model(&model.Category{}).Scopes(siblingCategories(parentID)).
+		Select("COALESCE(MAX(position), 0)").Scan(&max).Error
+	return max + 1, err
+}
+
+/**
+ * ReorderCategories sets the position of the children of a parent (nil for
+ * root categories) following categoryIDs. Siblings left out keep their
+ * relative order after the listed ones.
+ */
+func ReorderCategories(parentID *uint, categoryIDs []uint) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var current []uint
+		if err := tx.Model(&model.Category{}).Scopes(siblingCategories(parentID)).
+			Order("position ASC").Order("LOWER(name) ASC").
+			Pluck("id", &current).Error; err != nil {
+			return err
+		}
+
+		isSibling := make(map[uint]bool, len(current))
+		for _, id := range current {
+			isSibling[id] = true
+		}
+
+		ordered := make([]uint, 0, len(current))
+		listed := make(map[uint]bool, len(categoryIDs))
+		for _, id := range categoryIDs {
+			if !isSibling[id] {
+				return fmt.Errorf("categoria %d não pertence a este nível", id)
+			}
+			if listed[id] {
+				return fmt.Errorf("categoria %d repetida na ordenação", id)
+			}
+			listed[id] = true
+			ordered = append(ordered, id)
+		}
+		for _, id := range current {
+			if !listed[id] {
+				ordered = append(ordered, id)
+			}
+		}
+
+		for i, id := range ordered {
+			if err := tx.Model(&model.Category{}).Where("id = ?", id).UpdateColumn("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // GetCategoryByID retorna uma categoria pelo seu ID
 func GetCategoryByID(categoryID uint) (*model.Category, error) {
 	var category model.Category
@@ -38,12 +135,111 @@ func GetCategoryByID(categoryID uint) (*model.Category, error) {
 	return &category, nil
 }
 
-// DeleteCategory deleta uma categoria pelo seu ID (soft delete)
-func DeleteCategory(categoryID uint) error {
-	if err := config.DB.Delete(&model.Category{}, categoryID).Error; err != nil {
-		return err
+// GetCategoryBySlug retorna uma categoria pelo seu slug
+func GetCategoryBySlug(slug string) (*model.Category, error) {
+	var category model.Category
+	if err := config.DB.Where("slug = ?", slug).First(&category).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
 	}
-	return nil
+	return &category, nil
+}
+
+// GetCategoriesWithoutSlug retorna categorias (inclusive deletadas) que ainda não possuem slug
+func GetCategoriesWithoutSlug() ([]model.Category, error) {
+	var categories []model.Category
+	if err := config.DB.Unscoped().Where("slug IS NULL OR slug = ''").Order("id ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// UpdateCategorySlug grava apenas o slug da categoria, sem tocar nos demais campos
+func UpdateCategorySlug(categoryID uint, slug string) error {
+	return config.DB.Unscoped().Model(&model.Category{}).Where("id = ?", categoryID).UpdateColumn("slug", slug).Error
+}
+
+// CountProductsInCategories conta os produtos fora da lixeira que usam alguma das categorias, em qualquer status
+func CountProductsInCategories(categoryIDs []uint) (int64, error) {
+	var count int64
+	err := config.DB.Model(&model.Product{}).Where("category_id IN ?", categoryIDs).Count(&count).Error
+	return count, err
+}
+
+// GetProductIDsInCategories retorna os IDs dos produtos fora da lixeira que usam alguma das categorias, na ordem manual
+func GetProductIDsInCategories(categoryIDs []uint) ([]uint, error) {
+	var ids []uint
+	err := config.DB.Model(&model.Product{}).Where("category_id IN ?", categoryIDs).
+		Order("position ASC").Order("id ASC").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// CategoryDeletion descreve uma exclusão de categorias e o que acontece com subcategorias e produtos
+type CategoryDeletion struct {
+	CategoryIDs     []uint                // categorias enviadas para a lixeira
+	ReparentFrom    uint                  // categoria cujas filhas diretas mudam de mãe; 0 para nenhuma
+	ReparentTo      *uint                 // nova mãe das filhas de ReparentFrom; nil as torna de primeiro nível
+	MovedProducts   []ProductColumnUpdate // produtos transferidos para outra categoria
+	CascadeProducts bool                  // envia para a lixeira os produtos que ainda usam as categorias
+}
+
+/**
+ * DeleteCategories soft deletes categories in a single transaction, after
+ * reparenting children and moving products as described. Cascaded products
+ * get the same deletion time as the categories, so restoring a category with
+ * its products from the trash brings them back together.
+ *
+ * @returns - Number of products sent to the trash
+ */
+func DeleteCategories(deletion CategoryDeletion) (int64, error) {
+	var trashed int64
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if deletion.ReparentFrom != 0 {
+			if err := tx.Model(&model.Category{}).Where("parent_id = ?", deletion.ReparentFrom).
+				Updates(map[string]interface{}{"parent_id": deletion.ReparentTo, "version": gorm.Expr("version + 1")}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, update := range deletion.MovedProducts {
+			revision := model.ProductRevision{ProductID: update.Product.ID, Snapshot: update.Before}
+			if err := createProductRevision(tx, &revision); err != nil {
+				return err
+			}
+			if err := tx.Model(update.Product).Select(update.Columns).Updates(update.Product).Error; err != nil {
+				return err
+			}
+			if err := bumpProductVersions(tx, []uint{update.Product.ID}); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		if deletion.CascadeProducts {
+			result := tx.Model(&model.Product{}).Where("category_id IN ?", deletion.CategoryIDs).UpdateColumns(map[string]interface{}{
+				"deleted_at": now,
+				"version":    gorm.Expr("version + 1"),
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			trashed = result.RowsAffected
+		}
+
+		// Sem cascata, produtos restantes impedem a exclusão (podem ter sido criados depois da verificação)
+		var remaining int64
+		if err := tx.Model(&model.Product{}).Where("category_id IN ?", deletion.CategoryIDs).Count(&remaining).Error; err != nil {
+			return err
+		}
+		if remaining > 0 {
+			return ErrCategoryInUse
+		}
+
+		return tx.Model(&model.Category{}).Where("id IN ?", deletion.CategoryIDs).UpdateColumn("deleted_at", now).Error
+	})
+	return trashed, err
 }
 
 // HardDeleteCategory deleta permanentemente uma categoria (apenas para admin)
@@ -54,10 +250,27 @@ func HardDeleteCategory(categoryID uint) error {
 	return nil
 }
 
-// UpdateCategory atualiza uma categoria no banco de dados
+// UpdateCategory grava a categoria se a versão não mudou desde a leitura (senão retorna ErrVersionConflict), registrando o slug antigo se ele mudou
 func UpdateCategory(category *model.Category) error {
-	if err := config.DB.Save(category).Error; err != nil {
-		return err
+	version := category.Version
+	category.Version++
+
+	err := updateWithSlugHistory(model.SlugEntityCategory, &model.Category{}, category.ID, category.Slug, func(tx *gorm.DB) error {
+		result := tx.Model(category).
+			Where("version = ?", version).
+			Select("*").
+			Omit(clause.Associations, "id", "created_at").
+			Updates(category)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+		return nil
+	})
+	if err != nil {
+		category.Version = version
 	}
-	return nil
+	return err
 }
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrCouponDisabled indica que o cupom está desativado
+	ErrCouponDisabled = errors.New("cupom desativado")
+	// ErrCouponNotStarted indica que o período de validade do cupom ainda não começou
+	ErrCouponNotStarted = errors.New("o cupom ainda não está valendo")
+	// ErrCouponExpired indica que o período de validade do cupom já terminou
+	ErrCouponExpired = errors.New("cupom expirado")
+	// ErrCouponExhausted indica que o cupom atingiu o limite total de usos
+	ErrCouponExhausted = errors.New("o cupom atingiu o limite de usos")
+	// ErrCouponUserLimit indica que o usuário já usou o cupom o máximo de vezes permitido
+	ErrCouponUserLimit = errors.New("você já usou este cupom o máximo de vezes permitido")
+)
+
+// CouponAvailability confere se o cupom está ativo e dentro do período de validade no instante informado
+func CouponAvailability(coupon *model.Coupon, at time.Time) error {
+	switch {
+	case !coupon.Enabled:
+		return ErrCouponDisabled
+	case coupon.StartAt != nil && at.Before(*coupon.StartAt):
+		return ErrCouponNotStarted
+	case coupon.EndAt != nil && at.After(*coupon.EndAt):
+		return ErrCouponExpired
+	}
+	return nil
+}
+
+// GetCoupons retorna todos os cupons, dos mais novos para os mais antigos
+func GetCoupons() ([]model.Coupon, error) {
+	var coupons []model.Coupon
+	if err := config.DB.Order("id DESC").Find(&coupons).Error; err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
+
+// GetCouponByID retorna um cupom pelo seu ID
+func GetCouponByID(couponID uint) (*model.Coupon, error) {
+	var coupon model.Coupon
+	if err := config.DB.First(&coupon, couponID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// GetCouponByCode retorna um cupom pelo código (já normalizado em maiúsculas)
+func GetCouponByCode(code string) (*model.Coupon, error) {
+	var coupon model.Coupon
+	if err := config.DB.Where("code = ?", code).First(&coupon).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// CreateCoupon cria um novo cupom
+func CreateCoupon(coupon *model.Coupon) error {
+	return config.DB.Create(coupon).Error
+}
+
+// UpdateCoupon grava os dados do cupom sem tocar no contador de usos, que só muda no resgate
+func UpdateCoupon(coupon *model.Coupon) error {
+	return config.DB.Model(coupon).
+		Select("*").
+		Omit("id", "created_at", "deleted_at", "redemption_count").
+		Updates(coupon).Error
+}
+
+// DeleteCoupon deleta um cupom (soft delete); retorna false se ele não existir
+func DeleteCoupon(couponID uint) (bool, error) {
+	result := config.DB.Delete(&model.Coupon{}, couponID)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountUserCouponRedemptions conta quantas vezes o usuário já usou o cupom
+func CountUserCouponRedemptions(couponID, userID uint) (int64, error) {
+	var count int64
+	err := config.DB.Model(&model.CouponRedemption{}).
+		Where("coupon_id = ? AND user_id = ?", couponID, userID).Count(&count).Error
+	return count, err
+}
+
+// GetCouponRedemptions retorna os usos de um cupom, dos mais recentes para os mais antigos
+func GetCouponRedemptions(couponID uint) ([]model.CouponRedemption, error) {
+	var redemptions []model.CouponRedemption
+	if err := config.DB.Where("coupon_id = ?", couponID).Order("id DESC").Find(&redemptions).Error; err != nil {
+		return nil, err
+	}
+	return redemptions, nil
+}
+
+/**
+ * RedeemCoupon records a use of a coupon. The coupon row is locked (SELECT
+ * ... FOR UPDATE) for the whole transaction, so concurrent redemptions of the
+ * same code run one after the other and the total and per-user limits are
+ * checked against committed counts. The coupon is checked again on the
+ * locked row, since it may have been disabled, expired or deleted after the
+ * order was quoted, and apply calculates the discount from the locked row.
+ *
+ * @param redemption - The use to record, with CouponID and UserID filled
+ * @param apply - Fills Subtotal and Discount from the locked coupon; its error aborts the redemption
+ * @returns - gorm.ErrRecordNotFound if the coupon was deleted, the
+ * CouponAvailability errors, or ErrCouponExhausted / ErrCouponUserLimit when
+ * a limit was reached
+ */
+func RedeemCoupon(redemption *model.CouponRedemption, apply func(coupon *model.Coupon) error) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var coupon model.Coupon
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&coupon, redemption.CouponID).Error; err != nil {
+			return err
+		}
+		if err := CouponAvailability(&coupon, time.Now().UTC()); err != nil {
+			return err
+		}
+		if coupon.MaxRedemptions > 0 && coupon.RedemptionCount >= coupon.MaxRedemptions {
+			return ErrCouponExhausted
+		}
+		if coupon.MaxRedemptionsPerUser > 0 {
+			var used int64
+			if err := tx.Model(&model.CouponRedemption{}).
+				Where("coupon_id = ? AND user_id = ?", coupon.ID, redemption.UserID).
+				Count(&used).Error; err != nil {
+				return err
+			}
+			if used >= int64(coupon.MaxRedemptionsPerUser) {
+				return ErrCouponUserLimit
+			}
+		}
+		if err := apply(&coupon); err != nil {
+			return err
+		}
+
+		if err := tx.Create(redemption).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Coupon{}).Where("id = ?", coupon.ID).
+			UpdateColumn("redemption_count", gorm.Expr("redemption_count + 1")).Error
+	})
+}
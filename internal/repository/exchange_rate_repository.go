@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// exchangeRateUpsert substitui a cotação já cadastrada para a mesma moeda e data
+var exchangeRateUpsert = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "currency"}, {Name: "effective_date"}},
+	DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+}
+
+// SaveExchangeRate cria ou substitui a cotação de uma moeda em uma data
+func SaveExchangeRate(rate *model.ExchangeRate) error {
+	return config.DB.Clauses(exchangeRateUpsert).Create(rate).Error
+}
+
+// SaveExchangeRates grava várias cotações em uma única transação
+func SaveExchangeRates(rates []model.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(exchangeRateUpsert).CreateInBatches(&rates, 500).Error
+	})
+}
+
+// GetExchangeRates lista as cotações, das mais recentes para as mais antigas; moeda vazia lista todas
+func GetExchangeRates(currency string) ([]model.ExchangeRate, error) {
+	var rates []model.ExchangeRate
+	query := config.DB.Order("effective_date desc").Order("currency")
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+	err := query.Find(&rates).Error
+	return rates, err
+}
+
+// GetEffectiveExchangeRate retorna a cotação em vigor na data (a mais recente até ela), ou nil
+func GetEffectiveExchangeRate(currency string, at time.Time) (*model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	err := config.DB.Where("currency = ? AND effective_date <= ?", currency, at).
+		Order("effective_date desc").Limit(1).Find(&rate).Error
+	if err != nil {
+		return nil, err
+	}
+	if rate.Currency == "" {
+		return nil, nil
+	}
+	return &rate, nil
+}
+
+// DeleteExchangeRate remove a cotação de uma moeda em uma data; retorna false se não existia
+func DeleteExchangeRate(currency string, effectiveDate time.Time) (bool, error) {
+	result := config.DB.Where("currency = ? AND effective_date = ?", currency, effectiveDate).Delete(&model.ExchangeRate{})
+	return result.RowsAffected > 0, result.Error
+}
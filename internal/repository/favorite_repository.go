@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddFavorite adiciona um produto aos favoritos do usuário; favoritar de novo não tem efeito
+func AddFavorite(userID, productID uint) error {
+	return config.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.Favorite{UserID: userID, ProductID: productID}).Error
+}
+
+// RemoveFavorite remove um produto dos favoritos do usuário
+func RemoveFavorite(userID, productID uint) error {
+	return config.DB.Where("user_id = ? AND product_id = ?", userID, productID).Delete(&model.Favorite{}).Error
+}
+
+/**
+ * GetUserFavoritesWithCount returns the user's favorite products that are
+ * visible in the store, most recently favorited first.
+ */
+func GetUserFavoritesWithCount(userID uint, limit, offset int) ([]model.Product, int64, error) {
+	var products []model.Product
+	var total int64
+
+	query := func() *gorm.DB {
+		return config.DB.Model(&model.Product{}).Scopes(visibleProducts).
+			Joins("JOIN favorites ON favorites.product_id = products.id AND favorites.user_id = ?", userID)
+	}
+
+	if err := query().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query().Preload("Category").Preload("Tags").
+		Order("favorites.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+// GetMostFavorited retorna os produtos (fora da lixeira) com mais favoritos
+func GetMostFavorited(limit int) ([]model.FavoriteReportItem, error) {
+	var items []model.FavoriteReportItem
+	err := config.DB.Model(&model.Favorite{}).
+		Select("products.id AS product_id, products.name, products.slug, COUNT(favorites.id) AS favorite_count").
+		Joins("JOIN products ON products.id = favorites.product_id AND products.deleted_at IS NULL").
+		Group("products.id, products.name, products.slug").
+		Order("favorite_count DESC").
+		Order("products.name ASC").
+		Limit(limit).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+)
+
+/**
+ * CountImageReferences counts how many products and categories point to an
+ * image, including records in the trash since they may still be restored.
+ * Product images are stored comma-separated, so the list is wrapped in commas
+ * to match whole entries only.
+ */
+func CountImageReferences(url string) (int64, error) {
+	var products int64
+	if err := config.DB.Unscoped().Model(&model.Product{}).
+		Where("',' || image_urls || ',' LIKE ?", "%,"+escapeLike(url)+",%").
+		Count(&products).Error; err != nil {
+		return 0, err
+	}
+
+	var categories int64
+	if err := config.DB.Unscoped().Model(&model.Category{}).
+		Where("image = ?", url).
+		Count(&categories).Error; err != nil {
+		return 0, err
+	}
+
+	return products + categories, nil
+}
+
+// escapeLike escapa os curingas do LIKE para buscar o texto literalmente
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+)
+
+/**
+ * ProductImportItem is one product to be written by ImportProducts.
+ * Product.ID is zero for new products. NewCategory is set when the product
+ * belongs to a category created by the same import. Previous holds the
+ * content of an existing product, stored as a revision before it is replaced.
+ */
+type ProductImportItem struct {
+	Product     *model.Product
+	NewCategory *model.Category
+	Previous    *model.ProductSnapshot
+}
+
+/**
+ * ImportProducts creates the new categories and upserts all products in a
+ * single transaction; any failure rolls the whole import back. Existing
+ * products are only updated if their version is still the one that was read,
+ * and their stock and rating are left untouched.
+ *
+ * @returns - ErrVersionConflict when a product was saved in between
+ */
+func ImportProducts(newCategories []*model.Category, items []ProductImportItem) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, category := range newCategories {
+			if err := tx.Create(category).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, item := range items {
+			if item.NewCategory != nil {
+				item.Product.CategoryID = item.NewCategory.ID
+			}
+
+			if item.Product.ID == 0 {
+				if err := tx.Create(item.Product).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			if item.Previous != nil {
+				revision := model.ProductRevision{ProductID: item.Product.ID, Snapshot: *item.Previous}
+				if err := createProductRevision(tx, &revision); err != nil {
+					return err
+				}
+			}
+			version := item.Product.Version
+			item.Product.Version++
+			if err := updateProductColumns(tx, item.Product, version); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetProductsByLowerNames retorna produtos cujo nome (sem diferenciar maiúsculas) está na lista
+func GetProductsByLowerNames(names []string) ([]model.Product, error) {
+	var products []model.Product
+	if len(names) == 0 {
+		return products, nil
+	}
+	if err := config.DB.Where("LOWER(name) IN ?", names).Order("id ASC").Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+)
+
+// ProductColumnUpdate descreve as colunas alteradas de um produto numa operação em lote
+type ProductColumnUpdate struct {
+	Before  model.ProductSnapshot // conteúdo anterior, guardado como revisão
+	Product *model.Product        // produto já com os novos valores
+	Columns []string              // colunas a gravar
+}
+
+// GetProductsByIDs retorna os produtos com os IDs informados, com suas tags, independente do status
+func GetProductsByIDs(productIDs []uint) ([]model.Product, error) {
+	var products []model.Product
+	if err := config.DB.Preload("Tags").Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// GetDeletedProductsByIDs retorna os produtos da lixeira com os IDs informados
+func GetDeletedProductsByIDs(productIDs []uint) ([]model.Product, error) {
+	var products []model.Product
+	if err := config.DB.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", productIDs).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// BulkDeleteProducts move vários produtos para a lixeira (soft delete)
+func BulkDeleteProducts(productIDs []uint) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := bumpProductVersions(tx, productIDs); err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", productIDs).Delete(&model.Product{}).Error
+	})
+}
+
+// BulkRestoreProducts tira vários produtos da lixeira
+func BulkRestoreProducts(productIDs []uint) error {
+	return config.DB.Unscoped().Model(&model.Product{}).
+		Where("id IN ? AND deleted_at IS NOT NULL", productIDs).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+}
+
+// BulkUpdateProductStatus altera o status de vários produtos, descartando agendamentos pendentes
+func BulkUpdateProductStatus(productIDs []uint, status model.ProductStatus) error {
+	return config.DB.Model(&model.Product{}).Where("id IN ?", productIDs).Updates(map[string]interface{}{
+		"status":       status,
+		"publish_at":   nil,
+		"unpublish_at": nil,
+		"version":      gorm.Expr("version + 1"),
+	}).Error
+}
+
+// BulkAddProductTags vincula as tags a vários produtos, criando as que ainda não existem e guardando antes a revisão de cada um; vínculos que já existem são ignorados
+func BulkAddProductTags(productIDs []uint, tags []model.Tag, before map[uint]model.ProductSnapshot) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := createMissingTags(tx, tags); err != nil {
+			return err
+		}
+		tagIDs := make([]uint, 0, len(tags))
+		for _, tag := range tags {
+			tagIDs = append(tagIDs, tag.ID)
+		}
+		if err := createProductRevisions(tx, productIDs, before); err != nil {
+			return err
+		}
+		if err := tx.Exec(
+			"INSERT INTO product_tags (product_id, tag_id) SELECT p.id, t.id FROM products p CROSS JOIN tags t "+
+				"WHERE p.id IN ? AND t.id IN ? ON CONFLICT DO NOTHING",
+			productIDs, tagIDs,
+		).Error; err != nil {
+			return err
+		}
+		return bumpProductVersions(tx, productIDs)
+	})
+}
+
+// BulkRemoveProductTags desvincula as tags de vários produtos, guardando antes a revisão de cada um
+func BulkRemoveProductTags(productIDs []uint, tagIDs []uint, before map[uint]model.ProductSnapshot) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := createProductRevisions(tx, productIDs, before); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM product_tags WHERE product_id IN ? AND tag_id IN ?", productIDs, tagIDs).Error; err != nil {
+			return err
+		}
+		return bumpProductVersions(tx, productIDs)
+	})
+}
+
+// createProductRevisions guarda o conteúdo anterior de cada produto como revisão
+func createProductRevisions(tx *gorm.DB, productIDs []uint, before map[uint]model.ProductSnapshot) error {
+	for _, id := range productIDs {
+		revision := model.ProductRevision{ProductID: id, Snapshot: before[id]}
+		if err := createProductRevision(tx, &revision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bumpProductVersions incrementa a versão dos produtos alterados, invalidando ETags em uso
+func bumpProductVersions(tx *gorm.DB, productIDs []uint) error {
+	return tx.Model(&model.Product{}).Where("id IN ?", productIDs).
+		UpdateColumn("version", gorm.Expr("version + 1")).Error
+}
+
+/**
+ * BulkUpdateProducts applies per-product column changes in one transaction,
+ * storing a revision with the previous content of each product first. Any
+ * failure rolls back the whole batch.
+ *
+ * @param updates - The products and the columns to change on each one
+ */
+func BulkUpdateProducts(updates []ProductColumnUpdate) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, update := range updates {
+			revision := model.ProductRevision{ProductID: update.Product.ID, Snapshot: update.Before}
+			if err := createProductRevision(tx, &revision); err != nil {
+				return err
+			}
+			if err := tx.Model(update.Product).Select(update.Columns).Updates(update.Product).Error; err != nil {
+				return err
+			}
+			if err := bumpProductVersions(tx, []uint{update.Product.ID}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
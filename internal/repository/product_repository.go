@@ -1,19 +1,114 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/jpeccia/lariharumi_croche_backend_go/config"
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+/**
+ * visibleProducts restricts a query to products the storefront may show:
+ * published (or drafts whose PublishAt has passed), not yet unpublished and
+ * not in a hidden category or under a hidden ancestor. Checking the times
+ * here keeps visibility exact between scheduler runs.
+ */
+func visibleProducts(db *gorm.DB) *gorm.DB {
+	condition, vars := visibleProductsCondition()
+	return db.Where(condition, vars...)
+}
+
+// hiddenCategoryIDs seleciona as categorias ocultas e todas as suas subcategorias
+const hiddenCategoryIDs = "WITH RECURSIVE hidden_categories AS (" +
+	"SELECT id FROM categories WHERE hidden " +
+	"UNION SELECT c.id FROM categories c JOIN hidden_categories h ON c.parent_id = h.id" +
+	") SELECT id FROM hidden_categories"
+
+// visibleProductsCondition devolve o SQL de visibilidade para uso em JOINs e subconsultas
+func visibleProductsCondition() (string, []interface{}) {
+	now := time.Now().UTC()
+	return "(products.status = ? OR (products.status = ? AND products.publish_at <= ?)) " +
+			"AND (products.unpublish_at IS NULL OR products.unpublish_at > ?) " +
+			"AND products.category_id NOT IN (" + hiddenCategoryIDs + ")",
+		[]interface{}{model.ProductStatusPublished, model.ProductStatusDraft, now, now}
+}
+
+// filterProducts aplica os filtros opcionais das listagens públicas
+func filterProducts(filter model.ProductFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if filter.Tag != "" {
+			db = db.Where("products.id IN (SELECT product_tags.product_id FROM product_tags "+
+				"JOIN tags ON tags.id = product_tags.tag_id WHERE tags.slug = ?)", filter.Tag)
+		}
+		// Produtos em estoque com quantidade zerada contam como indisponíveis
+		switch filter.Availability {
+		case model.AvailabilityInStock:
+			db = db.Where("products.availability = ? AND products.stock_quantity > 0", model.AvailabilityInStock)
+		case model.AvailabilityMadeToOrder:
+			db = db.Where("products.availability = ?", model.AvailabilityMadeToOrder)
+		case model.AvailabilityUnavailable:
+			db = db.Where("(products.availability = ? OR (products.availability = ? AND products.stock_quantity <= 0))",
+				model.AvailabilityUnavailable, model.AvailabilityInStock)
+		}
+		for _, attr := range filter.Attributes {
+			if attr.Value != "" {
+				db = db.Where("LOWER(products.attributes->>?) = LOWER(?)", attr.Key, attr.Value)
+			}
+			// O CASE evita erro de conversão quando o valor gravado não é numérico
+			numeric := "(CASE WHEN jsonb_typeof(products.attributes->?) = 'number' THEN (products.attributes->>?)::numeric END)"
+			if attr.Min != nil {
+				db = db.Where(numeric+" >= ?", attr.Key, attr.Key, *attr.Min)
+			}
+			if attr.Max != nil {
+				db = db.Where(numeric+" <= ?", attr.Key, attr.Key, *attr.Max)
+			}
+		}
+		return db
+	}
+}
+
+// orderProducts aplica a ordenação escolhida a uma consulta de produtos
+func orderProducts(sort model.ProductSort) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch sort {
+		case model.SortNewest:
+			return db.Order("products.created_at DESC")
+		case model.SortRating:
+			return db.Order("products.rating_average DESC").
+				Order("products.rating_count DESC").
+				Order("LOWER(products.name) ASC")
+		case model.SortPopular:
+			since := time.Now().UTC().AddDate(0, 0, -model.PopularityWindowDays)
+			return db.Order(clause.OrderBy{Expression: clause.Expr{
+				SQL: "(SELECT COALESCE(SUM(v.detail_views + v.image_views), 0) FROM product_view_dailies v " +
+					"WHERE v.product_id = products.id AND v.day >= ?) DESC, LOWER(products.name) ASC",
+				Vars:               []interface{}{since},
+				WithoutParentheses: true,
+			}})
+		case model.SortManual:
+			return db.Order("products.featured DESC").
+				Order("products.category_id ASC").
+				Order("products.position ASC").
+				Order("LOWER(products.name) ASC")
+		default:
+			return db.Order("LOWER(products.name) ASC")
+		}
+	}
+}
+
 // CreateProduct cria um novo produto no banco de dados
 func CreateProduct(product *model.Product) error {
-	if err := config.DB.Create(product).Error; err != nil {
-		return err
-	}
-	return nil
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := createMissingTags(tx, product.Tags); err != nil {
+			return err
+		}
+		return tx.Create(product).Error
+	})
 }
 
 // GetProductByID retorna um produto pelo seu ID
@@ -28,10 +123,146 @@ func GetProductByID(productID uint) (*model.Product, error) {
 	return &product, nil
 }
 
+// GetProductBySlug retorna um produto pelo seu slug
+func GetProductBySlug(slug string) (*model.Product, error) {
+	var product model.Product
+	if err := config.DB.Scopes(visibleProducts).Preload("Category").Preload("Tags").Where("slug = ?", slug).First(&product).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetVisibleProductByID retorna um produto pelo ID apenas se ele estiver visível na vitrine
+func GetVisibleProductByID(productID uint) (*model.Product, error) {
+	var product model.Product
+	if err := config.DB.Scopes(visibleProducts).Preload("Tags").First(&product, productID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetVisibleProductsByIDs retorna, dentre os IDs informados, os produtos visíveis na vitrine
+func GetVisibleProductsByIDs(productIDs []uint) ([]model.Product, error) {
+	var products []model.Product
+	if err := config.DB.Scopes(visibleProducts).Where("products.id IN ?", productIDs).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+/**
+ * GetRelatedCandidates returns visible products other than the given one,
+ * products from the same category first, as candidates for recommendations.
+ */
+func GetRelatedCandidates(product *model.Product, limit int) ([]model.Product, error) {
+	var products []model.Product
+	err := config.DB.Scopes(visibleProducts).Preload("Category").Preload("Tags").
+		Where("products.id <> ?", product.ID).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "(products.category_id = ?) DESC, products.updated_at DESC", Vars: []interface{}{product.CategoryID}, WithoutParentheses: true}}).
+		Limit(limit).
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// GetProductWithTags retorna um produto pelo ID com suas tags, independente do status
+func GetProductWithTags(productID uint) (*model.Product, error) {
+	var product model.Product
+	if err := config.DB.Preload("Category").Preload("Tags").First(&product, productID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// ErrInsufficientStock indica que o estoque não comporta a baixa solicitada
+var ErrInsufficientStock = errors.New("estoque insuficiente")
+
+// UpdateProductAvailability altera a disponibilidade, o estoque e o prazo de produção de um produto, guardando before como revisão na mesma transação
+func UpdateProductAvailability(productID uint, availability model.ProductAvailability, stockQuantity, leadTimeDays int, before model.ProductSnapshot) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		revision := model.ProductRevision{ProductID: productID, Snapshot: before}
+		if err := createProductRevision(tx, &revision); err != nil {
+			return err
+		}
+		result := tx.Model(&model.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+			"availability":   availability,
+			"stock_quantity": stockQuantity,
+			"lead_time_days": leadTimeDays,
+			"version":        gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+/**
+ * AdjustProductStock adds delta (negative for a sale) to the stock in a
+ * single conditional UPDATE, so concurrent decrements can never take the
+ * quantity below zero.
+ *
+ * @param productID - The product to adjust
+ * @param delta - Units to add or, when negative, to remove
+ * @returns - The stock after the change, ErrInsufficientStock or gorm.ErrRecordNotFound
+ */
+func AdjustProductStock(productID uint, delta int) (int, error) {
+	var product model.Product
+	result := config.DB.Model(&product).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "stock_quantity"}}}).
+		Where("id = ? AND stock_quantity + ? >= 0", productID, delta).
+		UpdateColumn("stock_quantity", gorm.Expr("stock_quantity + ?", delta))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := config.DB.Model(&model.Product{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+			return 0, err
+		}
+		if count == 0 {
+			return 0, gorm.ErrRecordNotFound
+		}
+		return 0, ErrInsufficientStock
+	}
+	return product.StockQuantity, nil
+}
+
+// GetProductsWithoutSlug retorna produtos (inclusive deletados) que ainda não possuem slug
+func GetProductsWithoutSlug() ([]model.Product, error) {
+	var products []model.Product
+	if err := config.DB.Unscoped().Where("slug IS NULL OR slug = ''").Order("id ASC").Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// UpdateProductSlug grava apenas o slug do produto, sem tocar nos demais campos
+func UpdateProductSlug(productID uint, slug string) error {
+	return config.DB.Unscoped().Model(&model.Product{}).Where("id = ?", productID).UpdateColumns(map[string]interface{}{
+		"slug":    slug,
+		"version": gorm.Expr("version + 1"),
+	}).Error
+}
+
 // GetProducts retorna todos os produtos
 func GetProducts() ([]model.Product, error) {
 	var products []model.Product
-	if err := config.DB.Find(&products).Error; err != nil {
+	if err := config.DB.Preload("Tags").Find(&products).Error; err != nil {
 		return nil, err
 	}
 	return products, nil
@@ -40,7 +271,7 @@ func GetProducts() ([]model.Product, error) {
 func GetPaginatedProducts(limit int, offset int) ([]model.Product, error) {
 	var products []model.Product
 
-	err := config.DB.Preload("Category").
+	err := config.DB.Scopes(visibleProducts).Preload("Category").
 		Order("LOWER(name) ASC").
 		Limit(limit).
 		Offset(offset).
@@ -54,18 +285,17 @@ func GetPaginatedProducts(limit int, offset int) ([]model.Product, error) {
 }
 
 // GetPaginatedProductsWithCount retorna produtos paginados com contagem total
-func GetPaginatedProductsWithCount(limit int, offset int) ([]model.Product, int64, error) {
+func GetPaginatedProductsWithCount(limit int, offset int, filter model.ProductFilter) ([]model.Product, int64, error) {
 	var products []model.Product
 	var total int64
 
 	// Conta o total de produtos
-	if err := config.DB.Model(&model.Product{}).Count(&total).Error; err != nil {
+	if err := config.DB.Model(&model.Product{}).Scopes(visibleProducts, filterProducts(filter)).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Busca os produtos com preload
-	err := config.DB.Preload("Category").
-		Order("LOWER(name) ASC").
+	err := config.DB.Scopes(visibleProducts, filterProducts(filter), orderProducts(filter.Sort)).Preload("Category").Preload("Tags").
 		Limit(limit).
 		Offset(offset).
 		Find(&products).Error
@@ -80,7 +310,7 @@ func GetPaginatedProductsWithCount(limit int, offset int) ([]model.Product, int6
 func SearchProductsByName(searchTerm string, limit, offset int) ([]model.Product, error) {
 	var products []model.Product
 
-	query := config.DB.Preload("Category").Where("name ILIKE ?", "%"+searchTerm+"%")
+	query := config.DB.Scopes(visibleProducts).Preload("Category").Where("name ILIKE ?", "%"+searchTerm+"%")
 
 	if limit > 0 {
 		query = query.Limit(limit).Offset(offset)
@@ -91,17 +321,17 @@ func SearchProductsByName(searchTerm string, limit, offset int) ([]model.Product
 }
 
 // SearchProductsByNameWithCount retorna produtos pesquisados com contagem total
-func SearchProductsByNameWithCount(searchTerm string, limit, offset int) ([]model.Product, int64, error) {
+func SearchProductsByNameWithCount(searchTerm string, limit, offset int, filter model.ProductFilter) ([]model.Product, int64, error) {
 	var products []model.Product
 	var total int64
 
 	// Conta o total de produtos que correspondem à pesquisa
-	if err := config.DB.Model(&model.Product{}).Where("name ILIKE ?", "%"+searchTerm+"%").Count(&total).Error; err != nil {
+	if err := config.DB.Model(&model.Product{}).Scopes(visibleProducts, filterProducts(filter)).Where("name ILIKE ?", "%"+searchTerm+"%").Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// Busca os produtos com preload
-	query := config.DB.Preload("Category").Where("name ILIKE ?", "%"+searchTerm+"%")
+	query := config.DB.Scopes(visibleProducts, filterProducts(filter), orderProducts(filter.Sort)).Preload("Category").Preload("Tags").Where("name ILIKE ?", "%"+searchTerm+"%")
 	if limit > 0 {
 		query = query.Limit(limit).Offset(offset)
 	}
@@ -110,17 +340,239 @@ func SearchProductsByNameWithCount(searchTerm string, limit, offset int) ([]mode
 	return products, total, err
 }
 
-func GetProductsByCategory(categoryID uint) ([]model.Product, error) {
+// GetProductsByCategory retorna os produtos visíveis de uma ou mais categorias
+func GetProductsByCategory(categoryIDs []uint, filter model.ProductFilter) ([]model.Product, error) {
+	var products []model.Product
+	if err := config.DB.Scopes(visibleProducts, filterProducts(filter), orderProducts(filter.Sort)).Preload("Category").Preload("Tags").Where("category_id IN ?", categoryIDs).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// GetFeaturedProducts retorna os produtos em destaque visíveis na vitrine
+func GetFeaturedProducts(limit int) ([]model.Product, error) {
 	var products []model.Product
-	if err := config.DB.Preload("Category").Where("category_id = ?", categoryID).Find(&products).Error; err != nil {
+	err := config.DB.Scopes(visibleProducts).Preload("Category").Preload("Tags").
+		Where("products.featured = ?", true).
+		Order("products.position ASC").
+		Order("products.updated_at DESC").
+		Limit(limit).
+		Find(&products).Error
+	if err != nil {
 		return nil, err
 	}
 	return products, nil
 }
 
+// SetProductFeatured marca ou desmarca um produto como destaque
+func SetProductFeatured(productID uint, featured bool) error {
+	result := config.DB.Model(&model.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"featured": featured,
+		"version":  gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// NextProductPosition retorna a próxima posição livre no final de uma categoria
+func NextProductPosition(categoryID uint) (int, error) {
+	var last int
+	err := config.DB.Model(&model.Product{}).
+		Where("category_id = ?", categoryID).
+		Select("COALESCE(MAX(position), 0)").
+		Scan(&last).Error
+	return last + 1, err
+}
+
+/**
+ * ReorderCategoryProducts sets the manual order of a category. The given IDs
+ * take positions 1..n; products of the category left out of the list keep
+ * their relative order after them.
+ *
+ * @param categoryID - The category being reordered
+ * @param productIDs - Product IDs in the desired order
+ */
+func ReorderCategoryProducts(categoryID uint, productIDs []uint) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var current []uint
+		if err := tx.Model(&model.Product{}).
+			Where("category_id = ?", categoryID).
+			Order("position ASC").Order("LOWER(name) ASC").
+			Pluck("id", &current).Error; err != nil {
+			return err
+		}
+
+		inCategory := make(map[uint]bool, len(current))
+		for _, id := range current {
+			inCategory[id] = true
+		}
+
+		ordered := make([]uint, 0, len(current))
+		listed := make(map[uint]bool, len(productIDs))
+		for _, id := range productIDs {
+			if !inCategory[id] {
+				return fmt.Errorf("produto %d não pertence à categoria %d", id, categoryID)
+			}
+			if listed[id] {
+				return fmt.Errorf("produto %d repetido na ordenação", id)
+			}
+			listed[id] = true
+			ordered = append(ordered, id)
+		}
+		for _, id := range current {
+			if !listed[id] {
+				ordered = append(ordered, id)
+			}
+		}
+
+		// Só os produtos que mudaram de posição ganham uma nova versão
+		for i, id := range ordered {
+			if err := tx.Model(&model.Product{}).Where("id = ? AND position <> ?", id, i+1).UpdateColumns(map[string]interface{}{
+				"position": i + 1,
+				"version":  gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+/**
+ * GetAdminProductsWithCount lists every product regardless of its status,
+ * for the admin panel. An empty status returns all of them; "scheduled"
+ * returns drafts with a future PublishAt.
+ */
+func GetAdminProductsWithCount(status, searchTerm string, limit, offset int) ([]model.Product, int64, error) {
+	var products []model.Product
+	var total int64
+
+	query := config.DB.Model(&model.Product{})
+	switch status {
+	case "":
+	case "scheduled":
+		query = query.Where("status = ? AND publish_at > ?", model.ProductStatusDraft, time.Now().UTC())
+	default:
+		query = query.Where("status = ?", status)
+	}
+	if searchTerm != "" {
+		query = query.Where("name ILIKE ?", "%"+searchTerm+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Category").Preload("Tags").
+		Order("updated_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+/**
+ * ApplyProductSchedule persists the scheduled state changes that are due:
+ * drafts whose PublishAt has passed become published, then published products
+ * whose UnpublishAt has passed become archived.
+ *
+ * @param now - Reference time for the schedule
+ * @returns - Number of products published and archived
+ */
+func ApplyProductSchedule(now time.Time) (int64, int64, error) {
+	published := config.DB.Model(&model.Product{}).
+		Where("status = ? AND publish_at <= ?", model.ProductStatusDraft, now).
+		Updates(map[string]interface{}{"status": model.ProductStatusPublished, "publish_at": nil, "version": gorm.Expr("version + 1")})
+	if published.Error != nil {
+		return 0, 0, published.Error
+	}
+
+	archived := config.DB.Model(&model.Product{}).
+		Where("status = ? AND unpublish_at <= ?", model.ProductStatusPublished, now).
+		Updates(map[string]interface{}{"status": model.ProductStatusArchived, "unpublish_at": nil, "version": gorm.Expr("version + 1")})
+	if archived.Error != nil {
+		return published.RowsAffected, 0, archived.Error
+	}
+
+	return published.RowsAffected, archived.RowsAffected, nil
+}
+
+// ErrVersionConflict indica que o registro foi alterado por outra gravação desde que foi lido
+var ErrVersionConflict = errors.New("o registro foi alterado por outra pessoa; recarregue e tente novamente")
+
+/**
+ * UpdateProduct saves the product only if its version is still the one that
+ * was read, incrementing it. Stock and rating columns are left out because
+ * they have atomic updates of their own. A changed slug is recorded in the
+ * slug history, and Tags, when not nil, replace the current ones, both in
+ * the same transaction.
+ *
+ * @returns - ErrVersionConflict when another save happened in between
+ */
 func UpdateProduct(product *model.Product) error {
-	if err := config.DB.Save(product).Error; err != nil {
-		return err
+	return updateProduct(product, nil)
+}
+
+/**
+ * UpdateProductWithRevision works like UpdateProduct and stores before as a
+ * revision in the same transaction, so a version conflict or a failed write
+ * leaves no revision behind.
+ *
+ * @param before - The content of the product as it was read
+ */
+func UpdateProductWithRevision(product *model.Product, before model.ProductSnapshot) error {
+	return updateProduct(product, &before)
+}
+
+func updateProduct(product *model.Product, before *model.ProductSnapshot) error {
+	version := product.Version
+	product.Version++
+
+	err := updateWithSlugHistory(model.SlugEntityProduct, &model.Product{}, product.ID, product.Slug, func(tx *gorm.DB) error {
+		if before != nil {
+			revision := model.ProductRevision{ProductID: product.ID, Snapshot: *before}
+			if err := createProductRevision(tx, &revision); err != nil {
+				return err
+			}
+		}
+		if err := updateProductColumns(tx, product, version); err != nil {
+			return err
+		}
+		if product.Tags == nil {
+			return nil
+		}
+		if err := createMissingTags(tx, product.Tags); err != nil {
+			return err
+		}
+		return tx.Model(product).Association("Tags").Replace(product.Tags)
+	})
+	if err != nil {
+		product.Version = version
+	}
+	return err
+}
+
+// updateProductColumns grava o conteúdo editável do produto somente se a versão gravada ainda for a lida
+func updateProductColumns(tx *gorm.DB, product *model.Product, version int) error {
+	result := tx.Model(product).
+		Where("version = ?", version).
+		Select("*").
+		Omit(clause.Associations, "id", "created_at", "stock_quantity", "rating_average", "rating_count").
+		Updates(product)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
 	}
 	return nil
 }
@@ -135,11 +587,12 @@ func JoinImageUrls(imagePaths []string) string {
 
 // DeleteProduct deleta um produto pelo seu ID (soft delete)
 func DeleteProduct(productID uint) error {
-	var product model.Product
-	if err := config.DB.Delete(&product, productID).Error; err != nil {
-		return err
-	}
-	return nil
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := bumpProductVersions(tx, []uint{productID}); err != nil {
+			return err
+		}
+		return tx.Delete(&model.Product{}, productID).Error
+	})
 }
 
 // HardDeleteProduct deleta permanentemente um produto (apenas para admin)
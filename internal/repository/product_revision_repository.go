@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/**
+ * CreateProductRevision stores a snapshot with the next revision number for
+ * the product. The product row is locked so concurrent saves get distinct numbers.
+ */
+func CreateProductRevision(productID uint, snapshot model.ProductSnapshot) (*model.ProductRevision, error) {
+	revision := model.ProductRevision{ProductID: productID, Snapshot: snapshot}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return createProductRevision(tx, &revision)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+/**
+ * createProductRevision numbers and stores a revision inside an existing
+ * transaction. The tags of the snapshot are read from the database, so
+ * callers do not need to preload them; it must run before the tags change.
+ */
+func createProductRevision(tx *gorm.DB, revision *model.ProductRevision) error {
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").First(&model.Product{}, revision.ProductID).Error; err != nil {
+		return err
+	}
+
+	tags := []string{}
+	if err := tx.Table("tags").
+		Joins("JOIN product_tags ON product_tags.tag_id = tags.id").
+		Where("product_tags.product_id = ?", revision.ProductID).
+		Order("tags.name ASC").
+		Pluck("tags.name", &tags).Error; err != nil {
+		return err
+	}
+	revision.Snapshot.Tags = tags
+
+	var last int
+	if err := tx.Model(&model.ProductRevision{}).
+		Where("product_id = ?", revision.ProductID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&last).Error; err != nil {
+		return err
+	}
+
+	revision.Revision = last + 1
+	return tx.Create(revision).Error
+}
+
+// GetProductRevisions retorna as revisões de um produto, da mais recente para a mais antiga
+func GetProductRevisions(productID uint) ([]model.ProductRevision, error) {
+	var revisions []model.ProductRevision
+	if err := config.DB.Where("product_id = ?", productID).Order("revision DESC").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetProductRevision retorna uma revisão específica de um produto
+func GetProductRevision(productID uint, revisionNumber int) (*model.ProductRevision, error) {
+	var revision model.ProductRevision
+	if err := config.DB.Where("product_id = ? AND revision = ?", productID, revisionNumber).First(&revision).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// PruneProductRevisions mantém apenas as `keep` revisões mais recentes de um produto
+func PruneProductRevisions(productID uint, keep int) error {
+	newest := config.DB.Model(&model.ProductRevision{}).
+		Select("id").
+		Where("product_id = ?", productID).
+		Order("revision DESC").
+		Limit(keep)
+
+	return config.DB.
+		Where("product_id = ? AND id NOT IN (?)", productID, newest).
+		Delete(&model.ProductRevision{}).Error
+}
+
+// DeleteProductRevisions remove todas as revisões de um produto
+func DeleteProductRevisions(productID uint) error {
+	return config.DB.Where("product_id = ?", productID).Delete(&model.ProductRevision{}).Error
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/**
+ * SaveViewBatch adds a batch of view counters to the daily aggregates and
+ * the co-view pairs in a single transaction. Existing rows are incremented
+ * with upserts, so concurrent instances never overwrite each other.
+ *
+ * @param views - Daily counters to add, one entry per product and day
+ * @param coViews - Co-view counters to add, already in both directions
+ */
+func SaveViewBatch(views []model.ProductViewDaily, coViews []model.ProductCoView) error {
+	if len(views) == 0 && len(coViews) == 0 {
+		return nil
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if len(views) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "product_id"}, {Name: "day"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"detail_views": gorm.Expr("product_view_dailies.detail_views + excluded.detail_views"),
+					"image_views":  gorm.Expr("product_view_dailies.image_views + excluded.image_views"),
+				}),
+			}).Create(&views).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(coViews) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "product_id"}, {Name: "related_product_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"count":      gorm.Expr("product_co_views.count + excluded.count"),
+					"updated_at": gorm.Expr("excluded.updated_at"),
+				}),
+			}).Create(&coViews).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetCoViewCounts retorna quantas vezes cada produto foi visto junto com o produto informado
+func GetCoViewCounts(productID uint) (map[uint]int64, error) {
+	var rows []model.ProductCoView
+	if err := config.DB.Where("product_id = ?", productID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.RelatedProductID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetTopViewedProducts soma as visualizações por produto desde a data informada, excluindo produtos na lixeira
+func GetTopViewedProducts(since time.Time, limit int) ([]model.ViewReportProduct, error) {
+	var items []model.ViewReportProduct
+	err := config.DB.Model(&model.ProductViewDaily{}).
+		Select("products.id AS product_id, products.name, products.slug, "+
+			"SUM(product_view_dailies.detail_views) AS detail_views, "+
+			"SUM(product_view_dailies.image_views) AS image_views, "+
+			"SUM(product_view_dailies.detail_views + product_view_dailies.image_views) AS total_views").
+		Joins("JOIN products ON products.id = product_view_dailies.product_id AND products.deleted_at IS NULL").
+		Where("product_view_dailies.day >= ?", since).
+		Group("products.id, products.name, products.slug").
+		Order("total_views DESC").
+		Limit(limit).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetDailyViewTotals retorna o total de visualizações de cada dia desde a data informada
+func GetDailyViewTotals(since time.Time) ([]model.ViewReportDay, error) {
+	var days []model.ViewReportDay
+	err := config.DB.Model(&model.ProductViewDaily{}).
+		Select("day, SUM(detail_views) AS detail_views, SUM(image_views) AS image_views").
+		Where("day >= ?", since).
+		Group("day").
+		Order("day ASC").
+		Scan(&days).Error
+	if err != nil {
+		return nil, err
+	}
+	return days, nil
+}
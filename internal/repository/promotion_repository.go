@@ -2,11 +2,19 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/jpeccia/lariharumi_croche_backend_go/config"
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
 )
 
+// promotionPrecedence ordena as promoções da que vence para a que perde: maior prioridade, início mais recente e, por fim, a mais nova
+func promotionPrecedence(db *gorm.DB) *gorm.DB {
+	return db.Order("priority DESC").Order("start_at DESC NULLS LAST").Order("id DESC")
+}
+
+// GetLatestPromotion retorna a última promoção alterada, ou nil se não houver nenhuma
 func GetLatestPromotion() (*model.Promotion, error) {
 	var p model.Promotion
 	err := config.DB.Order("updated_at desc").Limit(1).Find(&p).Error
@@ -19,6 +27,58 @@ func GetLatestPromotion() (*model.Promotion, error) {
 	return &p, nil
 }
 
+// GetPromotionsByPrecedence retorna todas as promoções, da que vence para a que perde
+func GetPromotionsByPrecedence() ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	if err := config.DB.Scopes(promotionPrecedence).Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// runningPromotions filtra as promoções ligadas e dentro da janela no instante informado
+func runningPromotions(at time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("enabled = ?", true).
+			Where("start_at IS NULL OR start_at <= ?", at).
+			Where("end_at IS NULL OR end_at >= ?", at)
+	}
+}
+
+// GetCurrentPromotion retorna a promoção ligada, dentro da janela, que vence no instante informado, ou nil
+func GetCurrentPromotion(at time.Time) (*model.Promotion, error) {
+	var p model.Promotion
+	err := config.DB.Scopes(runningPromotions(at), promotionPrecedence).Limit(1).Find(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	if p.ID == 0 {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+// GetRunningPromotions retorna as promoções ligadas e dentro da janela no instante informado, da que vence para a que perde
+func GetRunningPromotions(at time.Time) ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	if err := config.DB.Scopes(runningPromotions(at), promotionPrecedence).Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// GetPromotionByID retorna uma promoção pelo seu ID
+func GetPromotionByID(promotionID uint) (*model.Promotion, error) {
+	var p model.Promotion
+	if err := config.DB.First(&p, promotionID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
 func SavePromotion(p *model.Promotion) error {
 	if p.ID == 0 {
 		return errors.New("promotion ID ausente para Save")
@@ -29,3 +89,9 @@ func SavePromotion(p *model.Promotion) error {
 func CreatePromotion(p *model.Promotion) error {
 	return config.DB.Create(p).Error
 }
+
+// DeletePromotion deleta uma promoção (soft delete); retorna false se ela não existir
+func DeletePromotion(promotionID uint) (bool, error) {
+	result := config.DB.Delete(&model.Promotion{}, promotionID)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrReviewExists indica que o usuário já tem uma avaliação pendente ou aprovada para o produto
+var ErrReviewExists = errors.New("você já avaliou este produto")
+
+/**
+ * CreateReview stores a review unless the author already has a pending or
+ * approved one for the product. The product row is locked (SELECT ... FOR
+ * UPDATE) while checking, so two simultaneous posts cannot both pass.
+ *
+ * @returns - ErrReviewExists when the user already reviewed the product
+ */
+func CreateReview(review *model.Review) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").First(&model.Product{}, review.ProductID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&model.Review{}).
+			Where("product_id = ? AND user_id = ? AND status <> ?", review.ProductID, review.UserID, model.ReviewStatusRejected).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrReviewExists
+		}
+		return tx.Create(review).Error
+	})
+}
+
+// GetReviewByID retorna uma avaliação pelo ID
+func GetReviewByID(reviewID uint) (*model.Review, error) {
+	var review model.Review
+	if err := config.DB.First(&review, reviewID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+// HasActiveReview indica se o usuário já tem uma avaliação pendente ou aprovada para o produto
+func HasActiveReview(productID, userID uint) (bool, error) {
+	var count int64
+	err := config.DB.Model(&model.Review{}).
+		Where("product_id = ? AND user_id = ? AND status <> ?", productID, userID, model.ReviewStatusRejected).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetApprovedReviewsWithCount retorna as avaliações aprovadas de um produto, das mais recentes para as mais antigas
+func GetApprovedReviewsWithCount(productID uint, limit, offset int) ([]model.Review, int64, error) {
+	var reviews []model.Review
+	var total int64
+
+	query := config.DB.Model(&model.Review{}).Where("product_id = ? AND status = ?", productID, model.ReviewStatusApproved)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&reviews).Error; err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// GetUserReviews retorna as avaliações escritas por um usuário, em qualquer status
+func GetUserReviews(userID uint) ([]model.Review, error) {
+	var reviews []model.Review
+	if err := config.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// GetReviewsByStatusWithCount lista avaliações para moderação; status vazio lista todas
+func GetReviewsByStatusWithCount(status model.ReviewStatus, limit, offset int) ([]model.Review, int64, error) {
+	var reviews []model.Review
+	var total int64
+
+	query := config.DB.Model(&model.Review{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	// A fila é atendida por ordem de chegada
+	if err := query.Order("created_at ASC").Limit(limit).Offset(offset).Find(&reviews).Error; err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+/**
+ * ModerateReview changes a review's status and recalculates the rating of
+ * its product in the same transaction, so the average never drifts from the
+ * approved reviews.
+ */
+func ModerateReview(review *model.Review, status model.ReviewStatus) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(review).Updates(map[string]interface{}{
+			"status":       status,
+			"moderated_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		review.Status = status
+		review.ModeratedAt = &now
+		return refreshProductRating(tx, review.ProductID)
+	})
+}
+
+// ReplyReview grava a resposta da loja a uma avaliação; resposta vazia remove a anterior
+func ReplyReview(review *model.Review, reply string) error {
+	var repliedAt *time.Time
+	if reply != "" {
+		now := time.Now()
+		repliedAt = &now
+	}
+	if err := config.DB.Model(review).Updates(map[string]interface{}{
+		"reply":      reply,
+		"replied_at": repliedAt,
+	}).Error; err != nil {
+		return err
+	}
+	review.Reply = reply
+	review.RepliedAt = repliedAt
+	return nil
+}
+
+// refreshProductRating recalcula a média e a quantidade de avaliações aprovadas sem alterar updated_at
+func refreshProductRating(tx *gorm.DB, productID uint) error {
+	return tx.Exec(`UPDATE products SET
+		rating_average = COALESCE((SELECT AVG(rating) FROM reviews WHERE product_id = @id AND status = @status AND deleted_at IS NULL), 0),
+		rating_count = (SELECT COUNT(*) FROM reviews WHERE product_id = @id AND status = @status AND deleted_at IS NULL)
+		WHERE id = @id`,
+		map[string]interface{}{"id": productID, "status": model.ReviewStatusApproved},
+	).Error
+}
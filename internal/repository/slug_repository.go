@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+)
+
+/**
+ * SlugTaken reports whether a slug is already used by another entity of the
+ * same type, either as its current slug or as one kept in the history.
+ * Soft-deleted rows are included so a restored record never collides.
+ *
+ * @param entityType - model.SlugEntityProduct or model.SlugEntityCategory
+ * @param slug - The candidate slug
+ * @param excludeID - ID of the entity being saved (0 when creating)
+ */
+func SlugTaken(entityType, slug string, excludeID uint) (bool, error) {
+	var table interface{} = &model.Product{}
+	if entityType == model.SlugEntityCategory {
+		table = &model.Category{}
+	}
+
+	var count int64
+	if err := config.DB.Unscoped().Model(table).
+		Where("slug = ? AND id <> ?", slug, excludeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if err := config.DB.Model(&model.SlugHistory{}).
+		Where("entity_type = ? AND slug = ? AND entity_id <> ?", entityType, slug, excludeID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+/**
+ * FindSlugHistory returns the history entry for an old slug, or nil if
+ * the slug was never used by an entity of the given type.
+ */
+func FindSlugHistory(entityType, slug string) (*model.SlugHistory, error) {
+	var entry model.SlugHistory
+	if err := config.DB.Where("entity_type = ? AND slug = ?", entityType, slug).First(&entry).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+/**
+ * saveSlugHistory records an old slug for an entity inside the transaction
+ * that renames it. If the entity is taking back a slug it used before, that
+ * history row is removed instead.
+ *
+ * @param entityType - model.SlugEntityProduct or model.SlugEntityCategory
+ * @param entityID - The renamed entity
+ * @param oldSlug - The slug that is being replaced
+ * @param newSlug - The slug the entity now uses
+ */
+func saveSlugHistory(tx *gorm.DB, entityType string, entityID uint, oldSlug, newSlug string) error {
+	if err := tx.Unscoped().
+		Where("entity_type = ? AND slug IN ?", entityType, []string{oldSlug, newSlug}).
+		Delete(&model.SlugHistory{}).Error; err != nil {
+		return err
+	}
+	if oldSlug == "" {
+		return nil
+	}
+	return tx.Create(&model.SlugHistory{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Slug:       oldSlug,
+	}).Error
+}
+
+/**
+ * updateWithSlugHistory runs a versioned update and, when it changed the
+ * entity's slug, records the previous one, all in one transaction. A failed
+ * or conflicting update therefore never leaves a history row behind.
+ *
+ * @param entityType - model.SlugEntityProduct or model.SlugEntityCategory
+ * @param table - An empty model of the entity, used to read the stored slug
+ * @param entityID - The entity being saved
+ * @param newSlug - The slug being saved
+ * @param update - The versioned update; ErrVersionConflict rolls everything back
+ */
+func updateWithSlugHistory(entityType string, table interface{}, entityID uint, newSlug string, update func(tx *gorm.DB) error) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var previousSlug string
+		if err := tx.Unscoped().Model(table).Where("id = ?", entityID).
+			Select("slug").Scan(&previousSlug).Error; err != nil {
+			return err
+		}
+		if err := update(tx); err != nil {
+			return err
+		}
+		if previousSlug == newSlug {
+			return nil
+		}
+		return saveSlugHistory(tx, entityType, entityID, previousSlug, newSlug)
+	})
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+)
+
+/**
+ * GetTagsWithCount returns every tag with the number of storefront-visible
+ * products using it, ordered by name.
+ */
+func GetTagsWithCount() ([]model.TagWithCount, error) {
+	condition, vars := visibleProductsCondition()
+
+	var tags []model.TagWithCount
+	err := config.DB.Model(&model.Tag{}).
+		Select("tags.*, COUNT(products.id) AS product_count").
+		Joins("LEFT JOIN product_tags ON product_tags.tag_id = tags.id").
+		Joins("LEFT JOIN products ON products.id = product_tags.product_id AND products.deleted_at IS NULL AND "+condition, vars...).
+		Group("tags.id").
+		Order("LOWER(tags.name) ASC").
+		Scan(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetTagByID retorna uma tag pelo ID
+func GetTagByID(tagID uint) (*model.Tag, error) {
+	var tag model.Tag
+	if err := config.DB.First(&tag, tagID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetTagsBySlugs retorna as tags cujos slugs estão na lista
+func GetTagsBySlugs(slugs []string) ([]model.Tag, error) {
+	var tags []model.Tag
+	if len(slugs) == 0 {
+		return tags, nil
+	}
+	if err := config.DB.Where("slug IN ?", slugs).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetProductTagSlugs retorna os slugs das tags de cada produto informado
+func GetProductTagSlugs(productIDs []uint) (map[uint][]string, error) {
+	var rows []struct {
+		ProductID uint
+		Slug      string
+	}
+	err := config.DB.Table("product_tags").
+		Select("product_tags.product_id, tags.slug").
+		Joins("JOIN tags ON tags.id = product_tags.tag_id").
+		Where("product_tags.product_id IN ?", productIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make(map[uint][]string, len(productIDs))
+	for _, row := range rows {
+		slugs[row.ProductID] = append(slugs[row.ProductID], row.Slug)
+	}
+	return slugs, nil
+}
+
+// CreateTag cria uma nova tag
+func CreateTag(tag *model.Tag) error {
+	return config.DB.Create(tag).Error
+}
+
+// createMissingTags cria, dentro da transação, as tags ainda não gravadas (ID zero)
+func createMissingTags(tx *gorm.DB, tags []model.Tag) error {
+	for i := range tags {
+		if tags[i].ID != 0 {
+			continue
+		}
+		if err := tx.Create(&tags[i]).Error; err != nil {
+			return fmt.Errorf("erro ao criar tag %q: %w", tags[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// UpdateTag atualiza uma tag
+func UpdateTag(tag *model.Tag) error {
+	return config.DB.Save(tag).Error
+}
+
+// DeleteTag remove uma tag e seus vínculos com produtos
+func DeleteTag(tagID uint) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_tags WHERE tag_id = ?", tagID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Tag{}, tagID).Error
+	})
+}
+
+/**
+ * MergeTags moves every product from the source tag to the target tag and
+ * deletes the source. Products that already had both keep a single link.
+ */
+func MergeTags(sourceID, targetID uint) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(
+			"INSERT INTO product_tags (product_id, tag_id) SELECT product_id, ? FROM product_tags WHERE tag_id = ? ON CONFLICT DO NOTHING",
+			targetID, sourceID,
+		).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM product_tags WHERE tag_id = ?", sourceID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Tag{}, sourceID).Error
+	})
+}
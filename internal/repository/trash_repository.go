@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/config"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrCategoryHasActiveProducts indica que a categoria ainda é usada por produtos ativos
+var ErrCategoryHasActiveProducts = errors.New("categoria possui produtos ativos")
+
+// GetDeletedProducts retorna os produtos que estão na lixeira (soft delete)
+func GetDeletedProducts() ([]model.Product, error) {
+	var products []model.Product
+	err := config.DB.Unscoped().
+		Preload("Category", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Tags").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// GetDeletedCategories retorna as categorias que estão na lixeira (soft delete)
+func GetDeletedCategories() ([]model.Category, error) {
+	var categories []model.Category
+	if err := config.DB.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// GetDeletedProductByID retorna um produto da lixeira pelo ID
+func GetDeletedProductByID(productID uint) (*model.Product, error) {
+	var product model.Product
+	if err := config.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&product, productID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetDeletedCategoryByID retorna uma categoria da lixeira pelo ID
+func GetDeletedCategoryByID(categoryID uint) (*model.Category, error) {
+	var category model.Category
+	if err := config.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&category, categoryID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+/**
+ * CountProductsDeletedWithCategory counts the products of a category that were
+ * deleted at the same time as, or after, the category itself.
+ */
+func CountProductsDeletedWithCategory(categoryID uint, categoryDeletedAt time.Time) (int64, error) {
+	var count int64
+	err := config.DB.Unscoped().Model(&model.Product{}).
+		Where("category_id = ? AND deleted_at >= ?", categoryID, categoryDeletedAt).
+		Count(&count).Error
+	return count, err
+}
+
+// RestoreProduct tira um produto da lixeira
+func RestoreProduct(productID uint) error {
+	return config.DB.Unscoped().Model(&model.Product{}).
+		Where("id = ? AND deleted_at IS NOT NULL", productID).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+}
+
+/**
+ * RestoreCategory takes a category out of the trash. When withProducts is set,
+ * the products deleted together with it are restored in the same transaction.
+ *
+ * @returns - Number of products restored
+ */
+func RestoreCategory(categoryID uint, categoryDeletedAt time.Time, withProducts bool) (int64, error) {
+	var restored int64
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&model.Category{}).
+			Where("id = ? AND deleted_at IS NOT NULL", categoryID).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		if !withProducts {
+			return nil
+		}
+
+		result := tx.Unscoped().Model(&model.Product{}).
+			Where("category_id = ? AND deleted_at >= ?", categoryID, categoryDeletedAt).
+			Updates(map[string]interface{}{
+				"deleted_at": nil,
+				"version":    gorm.Expr("version + 1"),
+			})
+		restored = result.RowsAffected
+		return result.Error
+	})
+
+	return restored, err
+}
+
+/**
+ * purgeProducts permanently removes soft-deleted products and the data that
+ * only exists for them (revisions and slug history).
+ *
+ * @returns - The images of the removed products, whose files the caller
+ * deletes once the transaction has committed
+ */
+func purgeProducts(tx *gorm.DB, productIDs []uint) ([]string, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+	var imageLists []string
+	if err := tx.Unscoped().Model(&model.Product{}).
+		Where("id IN ? AND deleted_at IS NOT NULL", productIDs).
+		Pluck("image_urls", &imageLists).Error; err != nil {
+		return nil, err
+	}
+	images := []string{}
+	for _, list := range imageLists {
+		images = append(images, ParseImageUrls(list)...)
+	}
+
+	if err := purgeProductData(tx, productIDs); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// purgeProductData apaga as linhas dos produtos e tudo o que pertence só a eles
+func purgeProductData(tx *gorm.DB, productIDs []uint) error {
+	if err := tx.Where("product_id IN ?", productIDs).Delete(&model.ProductRevision{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM product_tags WHERE product_id IN ?", productIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("product_id IN ?", productIDs).Delete(&model.Review{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("product_id IN ?", productIDs).Delete(&model.Favorite{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("product_id IN ?", productIDs).Delete(&model.ProductViewDaily{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("product_id IN ? OR related_product_id IN ?", productIDs, productIDs).Delete(&model.ProductCoView{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("entity_type = ? AND entity_id IN ?", model.SlugEntityProduct, productIDs).
+		Delete(&model.SlugHistory{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ? AND deleted_at IS NOT NULL", productIDs).Delete(&model.Product{}).Error
+}
+
+/**
+ * purgeCategory permanently removes a soft-deleted category together with its
+ * soft-deleted products. Fails if active products still point to it.
+ *
+ * @returns - The images of the category and of its removed products
+ */
+func purgeCategory(tx *gorm.DB, categoryID uint) ([]string, error) {
+	var active int64
+	if err := tx.Model(&model.Product{}).Where("category_id = ?", categoryID).Count(&active).Error; err != nil {
+		return nil, err
+	}
+	if active > 0 {
+		return nil, ErrCategoryHasActiveProducts
+	}
+
+	var productIDs []uint
+	if err := tx.Unscoped().Model(&model.Product{}).
+		Where("category_id = ? AND deleted_at IS NOT NULL", categoryID).
+		Pluck("id", &productIDs).Error; err != nil {
+		return nil, err
+	}
+	images, err := purgeProducts(tx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var categoryImages []string
+	if err := tx.Unscoped().Model(&model.Category{}).
+		Where("id = ? AND deleted_at IS NOT NULL", categoryID).
+		Pluck("image", &categoryImages).Error; err != nil {
+		return nil, err
+	}
+	images = append(images, categoryImages...)
+
+	if err := tx.Unscoped().Where("entity_type = ? AND entity_id = ?", model.SlugEntityCategory, categoryID).
+		Delete(&model.SlugHistory{}).Error; err != nil {
+		return nil, err
+	}
+	// Subcategorias (inclusive as da lixeira) passam a ser de primeiro nível
+	if err := tx.Unscoped().Model(&model.Category{}).Where("parent_id = ?", categoryID).
+		UpdateColumn("parent_id", nil).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", categoryID).Delete(&model.Category{}).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// PurgeProduct remove permanentemente um produto que está na lixeira e retorna as imagens que ele usava
+func PurgeProduct(productID uint) ([]string, error) {
+	var images []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		images, err = purgeProducts(tx, []uint{productID})
+		return err
+	})
+	return images, err
+}
+
+// PurgeCategory remove permanentemente uma categoria que está na lixeira e retorna as imagens que ela e seus produtos usavam
+func PurgeCategory(categoryID uint) ([]string, error) {
+	var images []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		images, err = purgeCategory(tx, categoryID)
+		return err
+	})
+	return images, err
+}
+
+// PurgeResult resume uma limpeza da lixeira; ImageUrls são as imagens dos registros removidos
+type PurgeResult struct {
+	Products   int
+	Categories int
+	ImageUrls  []string
+}
+
+/**
+ * PurgeDeletedBefore permanently removes every product and category that was
+ * moved to the trash before the given time. Categories still used by active
+ * products are skipped. On error the result still covers what was already
+ * committed, so the caller can clean up its images.
+ */
+func PurgeDeletedBefore(before time.Time) (PurgeResult, error) {
+	var result PurgeResult
+
+	var productIDs []uint
+	if err := config.DB.Unscoped().Model(&model.Product{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &productIDs).Error; err != nil {
+		return result, err
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		images, err := purgeProducts(tx, productIDs)
+		result.ImageUrls = images
+		return err
+	}); err != nil {
+		result.ImageUrls = nil
+		return result, err
+	}
+	result.Products = len(productIDs)
+
+	var categoryIDs []uint
+	if err := config.DB.Unscoped().Model(&model.Category{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &categoryIDs).Error; err != nil {
+		return result, err
+	}
+
+	for _, categoryID := range categoryIDs {
+		var images []string
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			images, err = purgeCategory(tx, categoryID)
+			return err
+		})
+		if err == ErrCategoryHasActiveProducts {
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.ImageUrls = append(result.ImageUrls, images...)
+		result.Categories++
+	}
+
+	return result, nil
+}
@@ -19,6 +19,7 @@ func SetupRouter() *gin.Engine {
 	r.Use(gzip.Gzip(gzip.DefaultCompression))
 	r.Use(config.CORSMiddleware())
 	r.Use(middleware.SecurityHeaders())
+	r.Use(middleware.Locale())
 
 	r.OPTIONS("/*any", func(c *gin.Context) {
 		c.Status(204)
@@ -40,7 +41,10 @@ func SetupRouter() *gin.Engine {
 	categories.Use(middleware.CacheControl(60, true))
 	{
 		categories.GET("", handler.GetCategories)
+		categories.GET("/tree", handler.GetCategoryTree)
 		categories.GET("/:id/image", handler.GetCategoryImage)
+		categories.GET("/slug/:slug", handler.GetCategoryBySlug)
+		categories.GET("/:id/attributes", handler.GetCategoryAttributeSchema)
 	}
 
 	products := r.Group("/products")
@@ -48,17 +52,37 @@ func SetupRouter() *gin.Engine {
 	{
 		products.GET("/category/:id", handler.GetProductsByCategory)
 		products.GET("", handler.GetProducts)
+		products.GET("/featured", handler.GetFeaturedProducts)
 		products.GET("/search", handler.SearchProducts)
 		products.GET("/:id/images", handler.GetProductImages)
+		products.GET("/slug/:slug", handler.GetProductBySlug)
+		products.GET("/:id/related", handler.GetRelatedProducts)
+		products.GET("/:id/reviews", handler.GetProductReviews)
 	}
 
+	r.GET("/tags", middleware.CacheControl(60, true), handler.GetTags)
+	r.GET("/exchange-rates", middleware.CacheControl(60, true), handler.GetCurrentExchangeRates)
+
 	r.GET("/promotion", handler.GetPromotion)
 
+	// Rotas de qualquer usuário logado
+	user := r.Group("").Use(middleware.AuthMiddleware(""))
+	{
+		user.POST("/products/:id/reviews", handler.CreateReview)
+		user.GET("/me/reviews", handler.GetMyReviews)
+		user.GET("/me/favorites", handler.GetMyFavorites)
+		user.POST("/me/favorites/:productId", handler.AddFavorite)
+		user.DELETE("/me/favorites/:productId", handler.RemoveFavorite)
+		user.POST("/coupons/validate", handler.ValidateCoupon)
+		user.POST("/coupons/redeem", handler.RedeemCoupon)
+	}
+
 	admin := r.Group("").Use(middleware.AuthMiddleware("ADMIN"))
 	{
 		admin.POST("/products", handler.CreateProduct)
 		admin.PATCH("/products/:id", handler.UpdateProduct)
 		admin.PUT("/categories/:id", handler.UpdateCategory)
+		admin.PATCH("/categories/:id", handler.UpdateCategory)
 		admin.DELETE("/products/:id", handler.DeleteProduct)
 		admin.POST("/categories", handler.CreateCategory)
 		admin.DELETE("/categories/:id", handler.DeleteCategory)
@@ -67,8 +91,63 @@ func SetupRouter() *gin.Engine {
 		admin.DELETE("/products/:id/images/:index", handler.DeleteProductImage)
 		admin.DELETE("/categories/:id/image", handler.DeleteCategoryImage)
 		admin.GET("/products/:id/upload-progress", handler.GetUploadProgress)
+		admin.PATCH("/products/:id/status", handler.UpdateProductStatus)
+		admin.PUT("/products/:id/availability", handler.UpdateProductAvailability)
+		admin.POST("/products/:id/stock", handler.AdjustProductStock)
+		admin.POST("/products/:id/duplicate", handler.DuplicateProduct)
+		admin.PUT("/products/:id/featured", handler.SetProductFeatured)
+		admin.PUT("/categories/:id/products/order", handler.ReorderCategoryProducts)
+		admin.PUT("/categories/:id/attributes", handler.UpdateCategoryAttributeSchema)
+		admin.GET("/products/:id/revisions", handler.GetProductRevisions)
+		admin.POST("/products/:id/revisions/:rev/restore", handler.RestoreProductRevision)
+		admin.GET("/admin/products", handler.GetAdminProducts)
+		admin.GET("/admin/products/:id", handler.GetAdminProduct)
+		admin.POST("/admin/products/bulk", handler.BulkUpdateProducts)
+		admin.GET("/admin/categories", handler.GetAdminCategories)
+		admin.PUT("/admin/categories/order", handler.ReorderCategories)
 
 		admin.PUT("/promotion", handler.UpdatePromotion)
+		admin.GET("/admin/promotions", handler.GetAdminPromotions)
+		admin.POST("/admin/promotions", handler.CreateAdminPromotion)
+		admin.GET("/admin/promotions/:id", handler.GetAdminPromotion)
+		admin.PUT("/admin/promotions/:id", handler.UpdateAdminPromotion)
+		admin.DELETE("/admin/promotions/:id", handler.DeleteAdminPromotion)
+
+		admin.GET("/admin/coupons", handler.GetCoupons)
+		admin.POST("/admin/coupons", handler.CreateCoupon)
+		admin.GET("/admin/coupons/:id", handler.GetCoupon)
+		admin.PUT("/admin/coupons/:id", handler.UpdateCoupon)
+		admin.DELETE("/admin/coupons/:id", handler.DeleteCoupon)
+		admin.GET("/admin/coupons/:id/redemptions", handler.GetCouponRedemptions)
+
+		admin.GET("/admin/trash", handler.GetTrash)
+		admin.POST("/admin/trash/products/:id/restore", handler.RestoreProduct)
+		admin.POST("/admin/trash/categories/:id/restore", handler.RestoreCategory)
+		admin.DELETE("/admin/trash/products/:id", handler.PurgeProduct)
+		admin.DELETE("/admin/trash/categories/:id", handler.PurgeCategory)
+
+		admin.POST("/admin/import/products", handler.ImportProducts)
+
+		admin.POST("/admin/tags", handler.CreateTag)
+		admin.PUT("/admin/tags/:id", handler.UpdateTag)
+		admin.DELETE("/admin/tags/:id", handler.DeleteTag)
+		admin.POST("/admin/tags/:id/merge", handler.MergeTags)
+
+		admin.GET("/admin/reviews", handler.GetReviewQueue)
+		admin.POST("/admin/reviews/:id/approve", handler.ApproveReview)
+		admin.POST("/admin/reviews/:id/reject", handler.RejectReview)
+		admin.POST("/admin/reviews/:id/reply", handler.ReplyReview)
+
+		admin.GET("/admin/reports/favorites", handler.GetMostFavoritedReport)
+		admin.GET("/admin/reports/views", handler.GetViewReport)
+		admin.GET("/admin/translations/missing", handler.GetMissingTranslations)
+		admin.GET("/admin/exchange-rates", handler.GetExchangeRates)
+		admin.POST("/admin/exchange-rates", handler.SaveExchangeRate)
+		admin.POST("/admin/exchange-rates/import", handler.ImportExchangeRates)
+		admin.DELETE("/admin/exchange-rates/:currency/:date", handler.DeleteExchangeRate)
+
+		admin.GET("/admin/backup", handler.ExportBackup)
+		admin.POST("/admin/restore", handler.RestoreBackup)
 	}
 
 	return r
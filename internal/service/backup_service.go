@@ -0,0 +1,360 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
+)
+
+// BackupFormatVersion é a versão do formato do arquivo de backup gerado por esta API
+const BackupFormatVersion = 1
+
+// Nomes dos arquivos dentro do zip de backup
+const (
+	backupManifestFile   = "manifest.json"
+	backupCategoriesFile = "categories.json"
+	backupProductsFile   = "products.json"
+	backupImagesFile     = "images.json"
+	backupPromotionsFile = "promotions.json"
+)
+
+// RestoreMode define como o backup é aplicado sobre o catálogo atual
+type RestoreMode string
+
+const (
+	RestoreModeReplace RestoreMode = "replace"
+	RestoreModeMerge   RestoreMode = "merge"
+)
+
+// BackupManifest descreve o conteúdo de um arquivo de backup
+type BackupManifest struct {
+	FormatVersion int            `json:"formatVersion"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	Files         []string       `json:"files"`
+	Counts        map[string]int `json:"counts"`
+}
+
+// BackupCategory é a representação de uma categoria no backup
+type BackupCategory struct {
+	ID              uint                  `json:"id"`
+	Name            string                `json:"name"`
+	Slug            string                `json:"slug"`
+	Description     string                `json:"description"`
+	Image           string                `json:"image"`
+	ParentID        *uint                 `json:"parentId,omitempty"`
+	Position        int                   `json:"position"`
+	Hidden          bool                  `json:"hidden"`
+	SEOTitle        string                `json:"seoTitle,omitempty"`
+	SEODescription  string                `json:"seoDescription,omitempty"`
+	AttributeSchema model.AttributeSchema `json:"attributeSchema,omitempty"`
+	Translations    model.Translations    `json:"translations,omitempty"`
+	CreatedAt       time.Time             `json:"createdAt"`
+	UpdatedAt       time.Time             `json:"updatedAt"`
+}
+
+// BackupProduct é a representação de um produto no backup; as imagens ficam em images.json
+type BackupProduct struct {
+	ID            uint                      `json:"id"`
+	Name          string                    `json:"name"`
+	Slug          string                    `json:"slug"`
+	Description   string                    `json:"description"`
+	PriceRange    string                    `json:"priceRange"`
+	CategoryID    uint                      `json:"categoryId"`
+	Position      int                       `json:"position"`
+	Featured      bool                      `json:"featured"`
+	Status        model.ProductStatus       `json:"status"`
+	PublishAt     *time.Time                `json:"publishAt"`
+	UnpublishAt   *time.Time                `json:"unpublishAt"`
+	Tags          []string                  `json:"tags,omitempty"`
+	Availability  model.ProductAvailability `json:"availability,omitempty"`
+	StockQuantity int                       `json:"stockQuantity"`
+	LeadTimeDays  int                       `json:"leadTimeDays"`
+	Attributes    model.ProductAttributes   `json:"attributes,omitempty"`
+	Translations  model.Translations        `json:"translations,omitempty"`
+	CreatedAt     time.Time                 `json:"createdAt"`
+	UpdatedAt     time.Time                 `json:"updatedAt"`
+}
+
+// BackupImage descreve uma imagem de produto e sua posição na galeria
+type BackupImage struct {
+	ProductID uint   `json:"productId"`
+	Position  int    `json:"position"`
+	URL       string `json:"url"`
+}
+
+// RestoreReport resume uma restauração de backup
+type RestoreReport struct {
+	Mode     RestoreMode                      `json:"mode"`
+	Manifest BackupManifest                   `json:"manifest"`
+	Result   *repository.CatalogRestoreResult `json:"result"`
+}
+
+/**
+ * ExportBackup writes a zip archive with the whole active catalog: categories,
+ * products, product image metadata and promotions, plus a versioned manifest.
+ *
+ * @param w - Destination of the zip archive
+ * @returns - The manifest written to the archive
+ */
+func ExportBackup(w io.Writer) (*BackupManifest, error) {
+	categories, err := repository.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	products, err := repository.GetProducts()
+	if err != nil {
+		return nil, err
+	}
+	promotions, err := repository.GetPromotions()
+	if err != nil {
+		return nil, err
+	}
+
+	backupCategories := make([]BackupCategory, 0, len(categories))
+	for _, c := range categories {
+		backupCategories = append(backupCategories, BackupCategory{
+			ID:              c.ID,
+			Name:            c.Name,
+			Slug:            c.Slug,
+			Description:     c.Description,
+			Image:           c.Image,
+			ParentID:        c.ParentID,
+			Position:        c.Position,
+			Hidden:          c.Hidden,
+			SEOTitle:        c.SEOTitle,
+			SEODescription:  c.SEODescription,
+			AttributeSchema: c.AttributeSchema,
+			Translations:    c.Translations,
+			CreatedAt:       c.CreatedAt,
+			UpdatedAt:       c.UpdatedAt,
+		})
+	}
+
+	backupProducts := make([]BackupProduct, 0, len(products))
+	backupImages := []BackupImage{}
+	for _, p := range products {
+		backupProducts = append(backupProducts, BackupProduct{
+			ID:            p.ID,
+			Name:          p.Name,
+			Slug:          p.Slug,
+			Description:   p.Description,
+			PriceRange:    p.PriceRange,
+			CategoryID:    p.CategoryID,
+			Position:      p.Position,
+			Featured:      p.Featured,
+			Status:        p.Status,
+			PublishAt:     p.PublishAt,
+			UnpublishAt:   p.UnpublishAt,
+			Tags:          model.TagNames(p.Tags),
+			Availability:  p.Availability,
+			StockQuantity: p.StockQuantity,
+			LeadTimeDays:  p.LeadTimeDays,
+			Attributes:    p.Attributes,
+			Translations:  p.Translations,
+			CreatedAt:     p.CreatedAt,
+			UpdatedAt:     p.UpdatedAt,
+		})
+		if p.ImageUrls == "" {
+			continue
+		}
+		for i, url := range repository.ParseImageUrls(p.ImageUrls) {
+			backupImages = append(backupImages, BackupImage{ProductID: p.ID, Position: i, URL: url})
+		}
+	}
+
+	manifest := &BackupManifest{
+		FormatVersion: BackupFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		Files:         []string{backupCategoriesFile, backupProductsFile, backupImagesFile, backupPromotionsFile},
+		Counts: map[string]int{
+			"categories": len(backupCategories),
+			"products":   len(backupProducts),
+			"images":     len(backupImages),
+			"promotions": len(promotions),
+		},
+	}
+
+	archive := zip.NewWriter(w)
+	entries := []struct {
+		name string
+		data interface{}
+	}{
+		{backupManifestFile, manifest},
+		{backupCategoriesFile, backupCategories},
+		{backupProductsFile, backupProducts},
+		{backupImagesFile, backupImages},
+		{backupPromotionsFile, promotions},
+	}
+	for _, entry := range entries {
+		if err := writeBackupEntry(archive, entry.name, entry.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+/**
+ * RestoreBackup reads an archive created by ExportBackup and applies it in a
+ * single transaction, either replacing the current catalog or merging into it.
+ *
+ * @param r - The zip archive
+ * @param size - Size of the archive in bytes
+ * @param mode - RestoreModeReplace or RestoreModeMerge
+ */
+func RestoreBackup(r io.ReaderAt, size int64, mode RestoreMode) (*RestoreReport, error) {
+	if mode != RestoreModeReplace && mode != RestoreModeMerge {
+		return nil, errors.New("modo inválido: use replace ou merge")
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("arquivo de backup inválido: %w", err)
+	}
+
+	var manifest BackupManifest
+	if err := readBackupEntry(archive, backupManifestFile, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > BackupFormatVersion {
+		return nil, fmt.Errorf("versão de backup %d não suportada (máxima: %d)", manifest.FormatVersion, BackupFormatVersion)
+	}
+
+	var backupCategories []BackupCategory
+	var backupProducts []BackupProduct
+	var backupImages []BackupImage
+	var promotions []model.Promotion
+	for name, dest := range map[string]interface{}{
+		backupCategoriesFile: &backupCategories,
+		backupProductsFile:   &backupProducts,
+		backupImagesFile:     &backupImages,
+		backupPromotionsFile: &promotions,
+	} {
+		if err := readBackupEntry(archive, name, dest); err != nil {
+			return nil, err
+		}
+	}
+
+	// Reconstrói a lista de imagens de cada produto na ordem original
+	sort.SliceStable(backupImages, func(i, j int) bool { return backupImages[i].Position < backupImages[j].Position })
+	images := map[uint][]string{}
+	for _, img := range backupImages {
+		images[img.ProductID] = append(images[img.ProductID], img.URL)
+	}
+
+	data := repository.CatalogData{Promotions: promotions}
+	categorySlugs := map[string]bool{}
+	for _, c := range backupCategories {
+		if c.Slug == "" {
+			if c.Slug, err = generateUniqueSlugReserving(model.SlugEntityCategory, c.Name, 0, categorySlugs); err != nil {
+				return nil, err
+			}
+		}
+		category := model.Category{
+			Name:            c.Name,
+			Slug:            c.Slug,
+			Description:     c.Description,
+			Image:           c.Image,
+			ParentID:        c.ParentID,
+			Position:        c.Position,
+			Hidden:          c.Hidden,
+			SEOTitle:        c.SEOTitle,
+			SEODescription:  c.SEODescription,
+			AttributeSchema: c.AttributeSchema,
+			Translations:    c.Translations,
+		}
+		category.ID = c.ID
+		category.CreatedAt = c.CreatedAt
+		category.UpdatedAt = c.UpdatedAt
+		data.Categories = append(data.Categories, category)
+	}
+
+	productSlugs := map[string]bool{}
+	for _, p := range backupProducts {
+		if p.Slug == "" {
+			if p.Slug, err = generateUniqueSlugReserving(model.SlugEntityProduct, p.Name, 0, productSlugs); err != nil {
+				return nil, err
+			}
+		}
+		if !p.Status.IsValid() {
+			p.Status = model.ProductStatusDraft
+		}
+		// Backups anteriores à disponibilidade não trazem o campo
+		if !p.Availability.IsValid() {
+			p.Availability = model.AvailabilityMadeToOrder
+		}
+		product := model.Product{
+			Name:          p.Name,
+			Slug:          p.Slug,
+			Description:   p.Description,
+			ImageUrls:     repository.JoinImageUrls(images[p.ID]),
+			PriceRange:    p.PriceRange,
+			CategoryID:    p.CategoryID,
+			Position:      p.Position,
+			Featured:      p.Featured,
+			Status:        p.Status,
+			PublishAt:     p.PublishAt,
+			UnpublishAt:   p.UnpublishAt,
+			Availability:  p.Availability,
+			StockQuantity: p.StockQuantity,
+			LeadTimeDays:  p.LeadTimeDays,
+			Attributes:    p.Attributes,
+			Translations:  p.Translations,
+		}
+		for _, name := range p.Tags {
+			if slug := util.Slugify(name); slug != "" {
+				product.Tags = append(product.Tags, model.Tag{Name: name, Slug: slug})
+			}
+		}
+		product.ID = p.ID
+		product.CreatedAt = p.CreatedAt
+		product.UpdatedAt = p.UpdatedAt
+		data.Products = append(data.Products, product)
+	}
+
+	result, err := repository.RestoreCatalog(data, mode == RestoreModeReplace)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao restaurar backup: %w", err)
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateAllCache()
+
+	return &RestoreReport{Mode: mode, Manifest: manifest, Result: result}, nil
+}
+
+// writeBackupEntry grava um arquivo JSON dentro do zip
+func writeBackupEntry(archive *zip.Writer, name string, data interface{}) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// readBackupEntry lê e decodifica um arquivo JSON do zip
+func readBackupEntry(archive *zip.Reader, name string, dest interface{}) error {
+	f, err := archive.Open(name)
+	if err != nil {
+		return fmt.Errorf("arquivo %s ausente no backup", name)
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(dest); err != nil {
+		return fmt.Errorf("erro ao ler %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
+)
+
+// MaxBulkProducts limita quantos produtos uma operação em lote pode alterar
+const MaxBulkProducts = 200
+
+/**
+ * BulkUpdateProducts applies one action to many products. Products that
+ * cannot take the action (missing, in a trashed category, over the tag limit,
+ * price without numbers) are reported individually; the remaining ones are
+ * changed together in a single transaction and the product cache is cleared once.
+ *
+ * @param op - The action, the product IDs and the action's parameters
+ * @returns - The result for every requested product
+ */
+func BulkUpdateProducts(op model.BulkProductOperation) (*model.BulkResult, error) {
+	if !op.Action.IsValid() {
+		return nil, errors.New("ação inválida: use delete, restore, move_category, change_status, add_tags, remove_tags ou adjust_price")
+	}
+
+	ids := uniqueIDs(op.ProductIDs)
+	if len(ids) == 0 {
+		return nil, errors.New("informe ao menos um produto em productIds")
+	}
+	if len(ids) > MaxBulkProducts {
+		return nil, fmt.Errorf("no máximo %d produtos por operação", MaxBulkProducts)
+	}
+
+	var products []model.Product
+	var err error
+	if op.Action == model.BulkRestore {
+		products, err = repository.GetDeletedProductsByIDs(ids)
+	} else {
+		products, err = repository.GetProductsByIDs(ids)
+	}
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	// Falhas individuais conhecidas antes de gravar
+	failures := map[uint]string{}
+	for _, id := range ids {
+		if byID[id] == nil {
+			if op.Action == model.BulkRestore {
+				failures[id] = "produto não encontrado na lixeira"
+			} else {
+				failures[id] = "produto não encontrado"
+			}
+		}
+	}
+
+	prices := map[uint]string{}
+	switch op.Action {
+	case model.BulkDelete:
+		err = bulkApply(ids, failures, repository.BulkDeleteProducts)
+	case model.BulkRestore:
+		err = bulkRestore(ids, byID, failures)
+	case model.BulkMoveCategory:
+		err = bulkMoveCategory(ids, byID, failures, op.CategoryID)
+	case model.BulkChangeStatus:
+		if !op.Status.IsValid() {
+			return nil, errors.New("status inválido: use draft, published ou archived")
+		}
+		err = bulkApply(ids, failures, func(valid []uint) error {
+			return repository.BulkUpdateProductStatus(valid, op.Status)
+		})
+	case model.BulkAddTags:
+		err = bulkAddTags(ids, byID, failures, op.Tags)
+	case model.BulkRemoveTags:
+		err = bulkRemoveTags(ids, byID, failures, op.Tags)
+	case model.BulkAdjustPrice:
+		err = bulkAdjustPrice(ids, byID, failures, op.Percent, prices)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.BulkResult{Action: op.Action, Results: make([]model.BulkItemResult, 0, len(ids))}
+	for _, id := range ids {
+		item := model.BulkItemResult{ProductID: id, Success: true, PriceRange: prices[id]}
+		if reason, failed := failures[id]; failed {
+			item = model.BulkItemResult{ProductID: id, Error: reason}
+			result.Failed++
+		} else {
+			result.Succeeded++
+		}
+		result.Results = append(result.Results, item)
+	}
+
+	if result.Succeeded > 0 {
+		cacheService := &CacheService{}
+		cacheService.InvalidateProductCache()
+	}
+
+	return result, nil
+}
+
+// uniqueIDs remove IDs repetidos ou zerados, mantendo a ordem
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+// bulkApply executa a alteração para os produtos sem falha, se houver algum
+func bulkApply(ids []uint, failures map[uint]string, apply func(valid []uint) error) error {
+	valid := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if _, failed := failures[id]; !failed {
+			valid = append(valid, id)
+		}
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+	return apply(valid)
+}
+
+// bulkRestore restaura os produtos cuja categoria não está na lixeira
+func bulkRestore(ids []uint, byID map[uint]*model.Product, failures map[uint]string) error {
+	categories := map[uint]bool{}
+	for _, id := range ids {
+		product := byID[id]
+		if product == nil {
+			continue
+		}
+		active, checked := categories[product.CategoryID]
+		if !checked {
+			category, err := repository.GetCategoryByID(product.CategoryID)
+			if err != nil {
+				return err
+			}
+			active = category != nil
+			categories[product.CategoryID] = active
+		}
+		if !active {
+			failures[id] = "a categoria do produto está na lixeira; restaure a categoria primeiro"
+		}
+	}
+	return bulkApply(ids, failures, repository.BulkRestoreProducts)
+}
+
+/**
+ * bulkMoveCategory moves products to another category. Moved products go to
+ * the end of the manual order of the new category, keeping their relative
+ * order, and attributes that do not exist in the new schema are dropped.
+ */
+func bulkMoveCategory(ids []uint, byID map[uint]*model.Product, failures map[uint]string, categoryID uint) error {
+	if categoryID == 0 {
+		return errors.New("categoryId é obrigatório para move_category")
+	}
+	category, err := repository.GetCategoryByID(categoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return errors.New("categoria não encontrada")
+	}
+
+	position, err := repository.NextProductPosition(categoryID)
+	if err != nil {
+		return fmt.Errorf("erro ao calcular posição: %w", err)
+	}
+
+	updates := []repository.ProductColumnUpdate{}
+	for _, id := range ids {
+		product := byID[id]
+		if product == nil || product.CategoryID == categoryID {
+			continue
+		}
+		before := model.SnapshotOf(product)
+		product.CategoryID = categoryID
+		product.Position = position
+		product.Attributes = category.AttributeSchema.Prune(product.Attributes)
+		position++
+
+		updates = append(updates, repository.ProductColumnUpdate{
+			Before:  before,
+			Product: product,
+			Columns: []string{"category_id", "position", "attributes"},
+		})
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return bulkUpdateWithRevisions(updates)
+}
+
+// bulkAddTags adiciona as tags aos produtos que não ultrapassam o limite de tags
+func bulkAddTags(ids []uint, byID map[uint]*model.Product, failures map[uint]string, names []string) error {
+	tags, err := resolveTags(names)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return errors.New("informe ao menos uma tag")
+	}
+
+	// Produtos que já têm todas as tags não mudam: ficam sem revisão e sem nova versão
+	changed := map[uint]bool{}
+	for _, id := range ids {
+		product := byID[id]
+		if product == nil {
+			continue
+		}
+		total := len(product.Tags)
+		for _, tag := range tags {
+			if tag.ID == 0 || !hasTag(product.Tags, tag.ID) {
+				total++
+			}
+		}
+		if total > MaxProductTags {
+			failures[id] = fmt.Sprintf("um produto pode ter no máximo %d tags", MaxProductTags)
+		}
+		changed[id] = total > len(product.Tags)
+	}
+
+	return bulkApply(onlyChanged(ids, changed), failures, func(valid []uint) error {
+		if err := repository.BulkAddProductTags(valid, tags, snapshotsOf(valid, byID)); err != nil {
+			return err
+		}
+		return pruneProductRevisions(valid)
+	})
+}
+
+// bulkRemoveTags remove as tags informadas; tags inexistentes são ignoradas
+func bulkRemoveTags(ids []uint, byID map[uint]*model.Product, failures map[uint]string, names []string) error {
+	slugs := tagSlugs(names)
+	if len(slugs) == 0 {
+		return errors.New("informe ao menos uma tag")
+	}
+	tags, err := repository.GetTagsBySlugs(slugs)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	tagIDs := make([]uint, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	// Produtos sem nenhuma das tags não mudam: ficam sem revisão e sem nova versão
+	changed := map[uint]bool{}
+	for _, id := range ids {
+		if product := byID[id]; product != nil {
+			for _, tagID := range tagIDs {
+				if hasTag(product.Tags, tagID) {
+					changed[id] = true
+					break
+				}
+			}
+		}
+	}
+	return bulkApply(onlyChanged(ids, changed), failures, func(valid []uint) error {
+		if err := repository.BulkRemoveProductTags(valid, tagIDs, snapshotsOf(valid, byID)); err != nil {
+			return err
+		}
+		return pruneProductRevisions(valid)
+	})
+}
+
+// bulkAdjustPrice reajusta os valores do preço de cada produto pelo percentual informado
+func bulkAdjustPrice(ids []uint, byID map[uint]*model.Product, failures map[uint]string, percent float64, prices map[uint]string) error {
+	if percent == 0 || percent <= -100 || percent > 1000 {
+		return errors.New("percent deve ser diferente de zero, maior que -100 e no máximo 1000")
+	}
+
+	updates := []repository.ProductColumnUpdate{}
+	for _, id := range ids {
+		product := byID[id]
+		if product == nil {
+			continue
+		}
+		adjusted, ok := util.AdjustPriceText(product.PriceRange, percent)
+		if !ok {
+			failures[id] = "o preço do produto não tem valor numérico"
+			continue
+		}
+		before := model.SnapshotOf(product)
+		product.PriceRange = adjusted
+		prices[id] = adjusted
+
+		updates = append(updates, repository.ProductColumnUpdate{
+			Before:  before,
+			Product: product,
+			Columns: []string{"price_range"},
+		})
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return bulkUpdateWithRevisions(updates)
+}
+
+// bulkUpdateWithRevisions grava as alterações numa transação e aplica a retenção de revisões
+func bulkUpdateWithRevisions(updates []repository.ProductColumnUpdate) error {
+	if err := repository.BulkUpdateProducts(updates); err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(updates))
+	for _, update := range updates {
+		ids = append(ids, update.Product.ID)
+	}
+	return pruneProductRevisions(ids)
+}
+
+// onlyChanged filtra os IDs dos produtos que a ação de fato altera, mantendo a ordem
+func onlyChanged(ids []uint, changed map[uint]bool) []uint {
+	filtered := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if changed[id] {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered
+}
+
+// snapshotsOf extrai o conteúdo atual dos produtos, guardado como revisão antes de uma alteração em lote
+func snapshotsOf(ids []uint, byID map[uint]*model.Product) map[uint]model.ProductSnapshot {
+	snapshots := make(map[uint]model.ProductSnapshot, len(ids))
+	for _, id := range ids {
+		if product := byID[id]; product != nil {
+			snapshots[id] = model.SnapshotOf(product)
+		}
+	}
+	return snapshots
+}
+
+// pruneProductRevisions aplica a retenção de revisões aos produtos alterados
+func pruneProductRevisions(ids []uint) error {
+	for _, id := range ids {
+		if err := repository.PruneProductRevisions(id, revisionRetention()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagSlugs converte nomes de tags nos respectivos slugs, sem repetições
+func tagSlugs(names []string) []string {
+	seen := map[string]bool{}
+	slugs := []string{}
+	for _, name := range names {
+		slug := util.Slugify(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+	}
+	return slugs
+}
+
+func hasTag(tags []model.Tag, tagID uint) bool {
+	for _, tag := range tags {
+		if tag.ID == tagID {
+			return true
+		}
+	}
+	return false
+}
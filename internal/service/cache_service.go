@@ -21,8 +21,8 @@ const (
 type CacheService struct{}
 
 // GetCachedProducts busca produtos no cache
-func (cs *CacheService) GetCachedProducts(limit, offset int) ([]model.Product, bool) {
-	key := cache.GenerateKey("products", limit, offset)
+func (cs *CacheService) GetCachedProducts(limit, offset int, locale string) ([]model.Product, bool) {
+	key := cache.GenerateKey("products", locale, limit, offset)
 	var products []model.Product
 
 	err := cache.Get(key, &products)
@@ -37,14 +37,14 @@ func (cs *CacheService) GetCachedProducts(limit, offset int) ([]model.Product, b
 }
 
 // SetCachedProducts armazena produtos no cache
-func (cs *CacheService) SetCachedProducts(products []model.Product, limit, offset int) {
-	key := cache.GenerateKey("products", limit, offset)
+func (cs *CacheService) SetCachedProducts(products []model.Product, limit, offset int, locale string) {
+	key := cache.GenerateKey("products", locale, limit, offset)
 	cache.Set(key, products, ProductTTL)
 }
 
 // GetCachedProductsByCategory busca produtos por categoria no cache
-func (cs *CacheService) GetCachedProductsByCategory(categoryID uint) ([]model.Product, bool) {
-	key := cache.GenerateKey("products_category", categoryID)
+func (cs *CacheService) GetCachedProductsByCategory(categoryID uint, filter model.ProductFilter) ([]model.Product, bool) {
+	key := cache.GenerateKey("products_category", categoryID, filter.CacheKey())
 	var products []model.Product
 
 	err := cache.Get(key, &products)
@@ -59,14 +59,14 @@ func (cs *CacheService) GetCachedProductsByCategory(categoryID uint) ([]model.Pr
 }
 
 // SetCachedProductsByCategory armazena produtos por categoria no cache
-func (cs *CacheService) SetCachedProductsByCategory(products []model.Product, categoryID uint) {
-	key := cache.GenerateKey("products_category", categoryID)
+func (cs *CacheService) SetCachedProductsByCategory(products []model.Product, categoryID uint, filter model.ProductFilter) {
+	key := cache.GenerateKey("products_category", categoryID, filter.CacheKey())
 	cache.Set(key, products, ProductTTL)
 }
 
 // GetCachedSearchProducts busca produtos pesquisados no cache
-func (cs *CacheService) GetCachedSearchProducts(searchTerm string, limit, offset int) ([]model.Product, bool) {
-	key := cache.GenerateKey("products_search", searchTerm, limit, offset)
+func (cs *CacheService) GetCachedSearchProducts(searchTerm string, limit, offset int, locale string) ([]model.Product, bool) {
+	key := cache.GenerateKey("products_search", locale, searchTerm, limit, offset)
 	var products []model.Product
 
 	err := cache.Get(key, &products)
@@ -81,14 +81,58 @@ func (cs *CacheService) GetCachedSearchProducts(searchTerm string, limit, offset
 }
 
 // SetCachedSearchProducts armazena produtos pesquisados no cache
-func (cs *CacheService) SetCachedSearchProducts(products []model.Product, searchTerm string, limit, offset int) {
-	key := cache.GenerateKey("products_search", searchTerm, limit, offset)
+func (cs *CacheService) SetCachedSearchProducts(products []model.Product, searchTerm string, limit, offset int, locale string) {
+	key := cache.GenerateKey("products_search", locale, searchTerm, limit, offset)
+	cache.Set(key, products, ProductTTL)
+}
+
+// GetCachedRelatedProducts busca produtos relacionados no cache
+func (cs *CacheService) GetCachedRelatedProducts(productID uint, limit int, locale string) ([]model.Product, bool) {
+	key := cache.GenerateKey("products_related", locale, productID, limit)
+	var products []model.Product
+
+	err := cache.Get(key, &products)
+	if err == redis.Nil {
+		return nil, false // Cache miss
+	}
+	if err != nil {
+		return nil, false // Erro no cache, não usar
+	}
+
+	return products, true // Cache hit
+}
+
+// SetCachedRelatedProducts armazena produtos relacionados no cache
+func (cs *CacheService) SetCachedRelatedProducts(products []model.Product, productID uint, limit int, locale string) {
+	key := cache.GenerateKey("products_related", locale, productID, limit)
+	cache.Set(key, products, ProductTTL)
+}
+
+// GetCachedFeaturedProducts busca produtos em destaque no cache
+func (cs *CacheService) GetCachedFeaturedProducts(limit int, locale string) ([]model.Product, bool) {
+	key := cache.GenerateKey("products_featured", locale, limit)
+	var products []model.Product
+
+	err := cache.Get(key, &products)
+	if err == redis.Nil {
+		return nil, false // Cache miss
+	}
+	if err != nil {
+		return nil, false // Erro no cache, não usar
+	}
+
+	return products, true // Cache hit
+}
+
+// SetCachedFeaturedProducts armazena produtos em destaque no cache
+func (cs *CacheService) SetCachedFeaturedProducts(products []model.Product, limit int, locale string) {
+	key := cache.GenerateKey("products_featured", locale, limit)
 	cache.Set(key, products, ProductTTL)
 }
 
 // GetCachedCategories busca categorias no cache
-func (cs *CacheService) GetCachedCategories() ([]model.Category, bool) {
-	key := "categories"
+func (cs *CacheService) GetCachedCategories(locale string) ([]model.Category, bool) {
+	key := cache.GenerateKey("categories", locale)
 	var categories []model.Category
 
 	err := cache.Get(key, &categories)
@@ -103,25 +147,25 @@ func (cs *CacheService) GetCachedCategories() ([]model.Category, bool) {
 }
 
 // SetCachedCategories armazena categorias no cache
-func (cs *CacheService) SetCachedCategories(categories []model.Category) {
-	key := "categories"
+func (cs *CacheService) SetCachedCategories(categories []model.Category, locale string) {
+	key := cache.GenerateKey("categories", locale)
 	cache.Set(key, categories, CategoryTTL)
 }
 
 // InvalidateProductCache invalida cache relacionado a produtos
 func (cs *CacheService) InvalidateProductCache() {
-	// Remove todos os caches de produtos
+	// "products*" cobre também products_category, products_search, products_related e products_featured
 	cache.DeletePattern("products*")
-	cache.DeletePattern("products_category*")
-	cache.DeletePattern("products_search*")
+	// As categorias trazem a contagem de produtos
+	cache.DeletePattern("categories*")
 }
 
 // InvalidateCategoryCache invalida cache relacionado a categorias
 func (cs *CacheService) InvalidateCategoryCache() {
-	// Remove cache de categorias
-	cache.Delete("categories")
-	// Também remove produtos por categoria pois podem ter mudado
-	cache.DeletePattern("products_category*")
+	// Remove cache de categorias, de todos os idiomas
+	cache.DeletePattern("categories*")
+	// Também remove produtos, que trazem a categoria e os breadcrumbs
+	cache.DeletePattern("products*")
 }
 
 // InvalidateAllCache invalida todo o cache
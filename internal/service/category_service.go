@@ -3,18 +3,65 @@ package service
 import (
 	"errors"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
 )
 
-// CreateCategory cria uma nova categoria
+// Limites recomendados para as meta tags de SEO
+const (
+	MaxSEOTitleLength       = 70
+	MaxSEODescriptionLength = 160
+)
+
+// ErrInvalidCategory indica dados de categoria rejeitados pela validação
+var ErrInvalidCategory = errors.New("categoria inválida")
+
+// validateCategorySEO confere o tamanho dos textos de SEO da categoria
+func validateCategorySEO(category *model.Category) error {
+	if utf8.RuneCountInString(category.SEOTitle) > MaxSEOTitleLength {
+		return fmt.Errorf("%w: seoTitle deve ter no máximo %d caracteres", ErrInvalidCategory, MaxSEOTitleLength)
+	}
+	if utf8.RuneCountInString(category.SEODescription) > MaxSEODescriptionLength {
+		return fmt.Errorf("%w: seoDescription deve ter no máximo %d caracteres", ErrInvalidCategory, MaxSEODescriptionLength)
+	}
+	return nil
+}
+
+// CreateCategory cria uma nova categoria, no fim da lista das categorias irmãs; falhas de validação envolvem ErrInvalidCategory
 func CreateCategory(category *model.Category) error {
 	if category.Name == "" {
-		return errors.New("nome da categoria é obrigatório")
+		return fmt.Errorf("%w: nome da categoria é obrigatório", ErrInvalidCategory)
+	}
+	if err := category.AttributeSchema.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCategory, err)
 	}
+	if err := validateCategorySEO(category); err != nil {
+		return err
+	}
+	if err := validateCategoryParent(0, category.ParentID); err != nil {
+		return err
+	}
+	position, err := repository.NextCategoryPosition(category.ParentID)
+	if err != nil {
+		return err
+	}
+	category.Position = position
+	translations, err := category.Translations.Normalize(model.CategoryTranslatableFields)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCategory, err)
+	}
+	category.Translations = translations
+
+	// Gera o slug único a partir do nome
+	slug, err := generateUniqueSlug(model.SlugEntityCategory, category.Name, 0)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar slug: %w", err)
+	}
+	category.Slug = slug
 
-	err := repository.CreateCategory(category)
+	err = repository.CreateCategory(category)
 	if err != nil {
 		return fmt.Errorf("erro ao criar categoria: %w", err)
 	}
@@ -26,42 +73,209 @@ func CreateCategory(category *model.Category) error {
 	return nil
 }
 
-// DeleteCategory deleta uma categoria pelo seu ID
-func DeleteCategory(categoryID uint) error {
-	// Opcional: verificar se a categoria possui produtos associados antes de deletar.
-	err := repository.DeleteCategory(categoryID)
+// CategoryDeleteOptions define o destino das subcategorias e dos produtos de uma categoria deletada
+type CategoryDeleteOptions struct {
+	Children        ChildrenAction // o que fazer com as subcategorias, vazio quando não há
+	MoveProductsTo  uint           // categoria que recebe os produtos; 0 para não mover
+	CascadeProducts bool           // envia os produtos para a lixeira junto com a categoria
+}
+
+// CategoryDeleteResult resume o que foi afetado pela exclusão de uma categoria
+type CategoryDeleteResult struct {
+	CategoriesDeleted int   `json:"categoriesDeleted"`
+	ProductsMoved     int   `json:"productsMoved"`
+	ProductsDeleted   int64 `json:"productsDeleted"`
+}
+
+/**
+ * DeleteCategory moves a category to the trash. A category with children
+ * needs an explicit action: ChildrenReparent moves them up to the deleted
+ * category's parent and ChildrenDelete trashes the whole subtree. Products of
+ * the deleted categories must either be moved to another category or sent to
+ * the trash along with them; otherwise ErrCategoryHasProducts is returned.
+ *
+ * @param categoryID - The category to delete
+ * @param opts - What to do with the subcategories and the products
+ */
+func DeleteCategory(categoryID uint, opts CategoryDeleteOptions) (*CategoryDeleteResult, error) {
+	if opts.MoveProductsTo != 0 && opts.CascadeProducts {
+		return nil, fmt.Errorf("%w: escolha moveTo ou cascade, não os dois", ErrInvalidCategoryTarget)
+	}
+
+	category, err := repository.GetCategoryByID(categoryID)
 	if err != nil {
-		return fmt.Errorf("erro ao deletar categoria: %w", err)
+		return nil, err
+	}
+	if category == nil {
+		return nil, errors.New("categoria não encontrada")
 	}
 
-	// Invalida cache relacionado a categorias
+	categories, err := repository.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	idx := newCategoryIndex(categories)
+
+	deletion := repository.CategoryDeletion{CategoryIDs: []uint{categoryID}}
+	switch {
+	case len(idx.children[categoryID]) == 0:
+	case opts.Children == ChildrenReparent:
+		deletion.ReparentFrom = categoryID
+		deletion.ReparentTo = category.ParentID
+	case opts.Children == ChildrenDelete:
+		deletion.CategoryIDs = idx.descendants(categoryID)
+	default:
+		return nil, ErrCategoryHasChildren
+	}
+
+	productIDs, err := repository.GetProductIDsInCategories(deletion.CategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(productIDs) > 0 {
+		switch {
+		case opts.MoveProductsTo != 0:
+			if deletion.MovedProducts, err = productsMovedOnDelete(productIDs, opts.MoveProductsTo, deletion.CategoryIDs); err != nil {
+				return nil, err
+			}
+		case opts.CascadeProducts:
+			deletion.CascadeProducts = true
+		default:
+			return nil, fmt.Errorf("%w (%d produto(s))", ErrCategoryHasProducts, len(productIDs))
+		}
+	}
+
+	trashed, err := repository.DeleteCategories(deletion)
+	if errors.Is(err, repository.ErrCategoryInUse) {
+		return nil, ErrCategoryHasProducts
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao deletar categoria: %w", err)
+	}
+	for _, update := range deletion.MovedProducts {
+		if err := repository.PruneProductRevisions(update.Product.ID, revisionRetention()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Invalida cache relacionado a categorias (e produtos, que carregam os breadcrumbs)
 	cacheService := &CacheService{}
 	cacheService.InvalidateCategoryCache()
 
-	return nil
+	return &CategoryDeleteResult{
+		CategoriesDeleted: len(deletion.CategoryIDs),
+		ProductsMoved:     len(deletion.MovedProducts),
+		ProductsDeleted:   trashed,
+	}, nil
+}
+
+// productsMovedOnDelete prepara a transferência dos produtos para a categoria de destino, como no move_category em lote
+func productsMovedOnDelete(productIDs []uint, targetID uint, deletedIDs []uint) ([]repository.ProductColumnUpdate, error) {
+	for _, id := range deletedIDs {
+		if id == targetID {
+			return nil, fmt.Errorf("%w: a categoria de destino também será deletada", ErrInvalidCategoryTarget)
+		}
+	}
+	target, err := repository.GetCategoryByID(targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: categoria de destino não encontrada", ErrInvalidCategoryTarget)
+	}
+
+	products, err := repository.GetProductsByIDs(productIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	position, err := repository.NextProductPosition(targetID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular posição: %w", err)
+	}
+
+	updates := make([]repository.ProductColumnUpdate, 0, len(products))
+	for _, id := range productIDs {
+		product := byID[id]
+		if product == nil {
+			continue
+		}
+		before := model.SnapshotOf(product)
+		product.CategoryID = targetID
+		product.Position = position
+		product.Attributes = target.AttributeSchema.Prune(product.Attributes)
+		position++
+
+		updates = append(updates, repository.ProductColumnUpdate{
+			Before:  before,
+			Product: product,
+			Columns: []string{"category_id", "position", "attributes"},
+		})
+	}
+	return updates, nil
 }
 
-// GetCategories retorna todas as categorias
-func GetCategories() ([]model.Category, error) {
+/**
+ * GetCategories returns the categories shown on the storefront, in display
+ * order and with the texts in the requested locale. Hidden categories and
+ * their subcategories are left out; ProductCount counts visible products.
+ */
+func GetCategories(locale string) ([]model.Category, error) {
 	cacheService := &CacheService{}
 
 	// Tenta buscar no cache primeiro
-	if categories, found := cacheService.GetCachedCategories(); found {
+	if categories, found := cacheService.GetCachedCategories(locale); found {
 		return categories, nil
 	}
 
 	// Se não encontrou no cache, busca no banco
-	categories, err := repository.GetCategories()
+	all, err := repository.GetCategoriesWithProductCount(true)
 	if err != nil {
 		return nil, err
 	}
 
+	categories := visibleCategories(all)
+	for i := range categories {
+		categories[i].Localize(locale)
+	}
+
 	// Armazena no cache
-	cacheService.SetCachedCategories(categories)
+	cacheService.SetCachedCategories(categories, locale)
 
 	return categories, nil
 }
 
+// GetAdminCategories retorna todas as categorias, inclusive as ocultas, com a contagem de produtos fora da lixeira (admin)
+func GetAdminCategories() ([]model.Category, error) {
+	return repository.GetCategoriesWithProductCount(false)
+}
+
+// ReorderCategories define a ordem das subcategorias de uma categoria mãe (nil para as de primeiro nível)
+func ReorderCategories(parentID *uint, categoryIDs []uint) error {
+	if parentID != nil {
+		parent, err := repository.GetCategoryByID(*parentID)
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			return errors.New("categoria mãe não encontrada")
+		}
+	}
+
+	if err := repository.ReorderCategories(parentID, categoryIDs); err != nil {
+		return err
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateCategoryCache()
+
+	return nil
+}
+
 // GetCategoryImage retorna a imagem da categoria
 func GetCategoryImage(categoryID uint) (string, error) {
 	category, err := repository.GetCategoryByID(categoryID)
@@ -95,32 +309,89 @@ func RemoveCategoryImage(categoryID uint) error {
 	if err != nil {
 		return err
 	}
+	if category == nil {
+		return errors.New("categoria não encontrada")
+	}
 
 	// Atualiza o banco de dados para remover a imagem da categoria
+	imagePath := category.Image
 	category.Image = ""
 	if err := repository.UpdateCategory(category); err != nil {
 		return err
 	}
 
+	// Remove o arquivo local apenas se nenhum outro registro ainda o utiliza
+	if _, err := deleteImageFileIfUnused(imagePath); err != nil {
+		return fmt.Errorf("erro ao remover arquivo da imagem: %w", err)
+	}
+
 	return nil
 }
 
-// UpdateCategory atualiza os dados de uma categoria no banco de dados
+// UpdateCategory atualiza os dados de uma categoria; com Version informada, falha se a categoria mudou desde a leitura.
+// Falhas de validação envolvem ErrInvalidCategory.
 func UpdateCategory(categoryID uint, updatedCategory *model.Category) error {
 	// Busca a categoria existente no banco de dados
 	category, err := repository.GetCategoryByID(categoryID)
 	if err != nil {
 		return err // Retorna erro se não encontrar o produto
 	}
-	// Atualiza os campos da categoria
+	if category == nil {
+		return errors.New("categoria não encontrada")
+	}
+	if updatedCategory.Version != 0 && updatedCategory.Version != category.Version {
+		return repository.ErrVersionConflict
+	}
+	if updatedCategory.Name == "" {
+		return fmt.Errorf("%w: nome da categoria é obrigatório", ErrInvalidCategory)
+	}
+	if err := validateCategorySEO(updatedCategory); err != nil {
+		return err
+	}
+	if err := validateCategoryParent(category.ID, updatedCategory.ParentID); err != nil {
+		return err
+	}
+
+	// Ao trocar de categoria mãe, a categoria vai para o fim da lista das novas irmãs
+	if !sameParent(category.ParentID, updatedCategory.ParentID) {
+		position, err := repository.NextCategoryPosition(updatedCategory.ParentID)
+		if err != nil {
+			return err
+		}
+		category.Position = position
+	}
+
+	// Se o nome mudou, gera um novo slug e guarda o antigo no histórico
+	if updatedCategory.Name != category.Name {
+		slug, err := renameSlug(model.SlugEntityCategory, category.ID, category.Slug, updatedCategory.Name)
+		if err != nil {
+			return fmt.Errorf("erro ao gerar slug: %w", err)
+		}
+		category.Slug = slug
+	}
+	updatedCategory.Slug = category.Slug
+
+	// Atualiza os campos da categoria; traduções ausentes mantêm as atuais
 	category.Name = updatedCategory.Name
 	category.Description = updatedCategory.Description
 	category.Image = updatedCategory.Image
+	category.ParentID = updatedCategory.ParentID
+	category.Hidden = updatedCategory.Hidden
+	category.SEOTitle = updatedCategory.SEOTitle
+	category.SEODescription = updatedCategory.SEODescription
+	if updatedCategory.Translations != nil {
+		translations, err := updatedCategory.Translations.Normalize(model.CategoryTranslatableFields)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCategory, err)
+		}
+		category.Translations = translations
+	}
 
 	// Salva as alterações no banco de dados
 	if err := repository.UpdateCategory(category); err != nil {
 		return err // Retorna erro se falhar ao atualizar no banco
 	}
+	*updatedCategory = *category
 
 	// Invalida cache relacionado a categorias
 	cacheService := &CacheService{}
@@ -128,3 +399,50 @@ func UpdateCategory(categoryID uint, updatedCategory *model.Category) error {
 
 	return nil
 }
+
+// GetCategoryAttributeSchema retorna o esquema de atributos de uma categoria
+func GetCategoryAttributeSchema(categoryID uint) (model.AttributeSchema, error) {
+	category, err := repository.GetCategoryByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, errors.New("categoria não encontrada")
+	}
+	if category.AttributeSchema == nil {
+		return model.AttributeSchema{}, nil
+	}
+	return category.AttributeSchema, nil
+}
+
+/**
+ * UpdateCategoryAttributeSchema replaces the custom fields of a category.
+ * Values already saved on products are kept; they are checked against the
+ * new schema the next time each product is edited.
+ *
+ * @param categoryID - The category to change
+ * @param schema - The complete list of fields, in form order
+ */
+func UpdateCategoryAttributeSchema(categoryID uint, schema model.AttributeSchema) (*model.Category, error) {
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+
+	category, err := repository.GetCategoryByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, errors.New("categoria não encontrada")
+	}
+
+	category.AttributeSchema = schema
+	if err := repository.UpdateCategory(category); err != nil {
+		return nil, err
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateCategoryCache()
+
+	return category, nil
+}
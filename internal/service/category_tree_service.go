@@ -0,0 +1,216 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+)
+
+// ChildrenAction define o que acontece com as subcategorias quando a categoria mãe é deletada
+type ChildrenAction string
+
+const (
+	// ChildrenReparent move as subcategorias para a categoria mãe da que foi deletada
+	ChildrenReparent ChildrenAction = "reparent"
+	// ChildrenDelete envia as subcategorias (e as delas) para a lixeira junto
+	ChildrenDelete ChildrenAction = "delete"
+)
+
+// ErrCategoryHasChildren indica que a categoria tem subcategorias e nenhuma ação foi escolhida
+var ErrCategoryHasChildren = errors.New("a categoria possui subcategorias: informe children=reparent ou children=delete")
+
+// ErrCategoryHasProducts indica que a categoria tem produtos e nenhum destino foi escolhido para eles
+var ErrCategoryHasProducts = errors.New("a categoria possui produtos: informe moveTo=<id> para transferi-los ou cascade=true para enviá-los à lixeira")
+
+// ErrInvalidCategoryTarget indica um destino inválido para os produtos da categoria deletada
+var ErrInvalidCategoryTarget = errors.New("destino dos produtos inválido")
+
+/**
+ * categoryIndex gives fast parent and children lookups over a flat list of
+ * categories. Parents missing from the list (deleted ones) are treated as
+ * absent, so their children show up as roots.
+ */
+type categoryIndex struct {
+	byID     map[uint]*model.Category
+	children map[uint][]uint
+	roots    []uint
+}
+
+func newCategoryIndex(categories []model.Category) *categoryIndex {
+	idx := &categoryIndex{byID: make(map[uint]*model.Category, len(categories)), children: map[uint][]uint{}}
+	for i := range categories {
+		idx.byID[categories[i].ID] = &categories[i]
+	}
+	for i := range categories {
+		c := &categories[i]
+		if c.ParentID != nil && idx.byID[*c.ParentID] != nil {
+			idx.children[*c.ParentID] = append(idx.children[*c.ParentID], c.ID)
+		} else {
+			idx.roots = append(idx.roots, c.ID)
+		}
+	}
+	return idx
+}
+
+// parentOf retorna a categoria mãe presente no índice, ou nil
+func (idx *categoryIndex) parentOf(c *model.Category) *model.Category {
+	if c.ParentID == nil {
+		return nil
+	}
+	return idx.byID[*c.ParentID]
+}
+
+// path retorna o caminho da raiz até a categoria; interrompe se encontrar um ciclo
+func (idx *categoryIndex) path(categoryID uint) []model.CategoryBreadcrumb {
+	var path []model.CategoryBreadcrumb
+	seen := map[uint]bool{}
+	for c := idx.byID[categoryID]; c != nil && !seen[c.ID]; c = idx.parentOf(c) {
+		seen[c.ID] = true
+		path = append([]model.CategoryBreadcrumb{{ID: c.ID, Name: c.Name, Slug: c.Slug}}, path...)
+	}
+	return path
+}
+
+// descendants retorna o ID da categoria seguido dos IDs de todas as suas subcategorias
+func (idx *categoryIndex) descendants(categoryID uint) []uint {
+	ids := []uint{categoryID}
+	seen := map[uint]bool{categoryID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range idx.children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// tree monta as categorias aninhadas a partir das raízes, na ordem da lista original
+func (idx *categoryIndex) tree() []model.Category {
+	var build func(ids []uint, seen map[uint]bool) []model.Category
+	build = func(ids []uint, seen map[uint]bool) []model.Category {
+		nodes := make([]model.Category, 0, len(ids))
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			node := *idx.byID[id]
+			node.Children = build(idx.children[id], seen)
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+	return build(idx.roots, map[uint]bool{})
+}
+
+// GetCategoryTree retorna as categorias visíveis aninhadas, com os textos no idioma pedido
+func GetCategoryTree(locale string) ([]model.Category, error) {
+	categories, err := GetCategories(locale)
+	if err != nil {
+		return nil, err
+	}
+	return newCategoryIndex(categories).tree(), nil
+}
+
+// GetAdminCategoryTree retorna todas as categorias aninhadas, inclusive as ocultas (admin)
+func GetAdminCategoryTree() ([]model.Category, error) {
+	categories, err := GetAdminCategories()
+	if err != nil {
+		return nil, err
+	}
+	return newCategoryIndex(categories).tree(), nil
+}
+
+// visibleCategories remove da lista as categorias ocultas e todas as subcategorias delas
+func visibleCategories(categories []model.Category) []model.Category {
+	idx := newCategoryIndex(categories)
+	hidden := map[uint]bool{}
+	for _, c := range categories {
+		if c.Hidden {
+			for _, id := range idx.descendants(c.ID) {
+				hidden[id] = true
+			}
+		}
+	}
+
+	visible := make([]model.Category, 0, len(categories))
+	for _, c := range categories {
+		if !hidden[c.ID] {
+			visible = append(visible, c)
+		}
+	}
+	return visible
+}
+
+// sameParent indica se duas referências de categoria mãe apontam para a mesma (ou ambas são nil)
+func sameParent(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+/**
+ * validateCategoryParent checks that parentID exists and that making it the
+ * parent of categoryID would not create a cycle, i.e. the parent is neither
+ * the category itself nor one of its descendants.
+ *
+ * @param categoryID - The category being saved, 0 when it is new
+ * @param parentID - The requested parent, nil for a root category
+ */
+func validateCategoryParent(categoryID uint, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == categoryID {
+		return fmt.Errorf("%w: a categoria não pode ser mãe de si mesma", ErrInvalidCategory)
+	}
+
+	categories, err := repository.GetCategories()
+	if err != nil {
+		return err
+	}
+	idx := newCategoryIndex(categories)
+	if idx.byID[*parentID] == nil {
+		return fmt.Errorf("%w: categoria mãe não encontrada", ErrInvalidCategory)
+	}
+	if categoryID == 0 {
+		return nil
+	}
+	for _, crumb := range idx.path(*parentID) {
+		if crumb.ID == categoryID {
+			return fmt.Errorf("%w: a categoria mãe não pode ser uma subcategoria dela mesma", ErrInvalidCategory)
+		}
+	}
+	return nil
+}
+
+// getCategoryDescendantIDs retorna o ID da categoria e os de todas as suas subcategorias
+func getCategoryDescendantIDs(categoryID uint) ([]uint, error) {
+	categories, err := repository.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	return newCategoryIndex(categories).descendants(categoryID), nil
+}
+
+// setBreadcrumbs preenche o caminho de categorias de cada produto, com os nomes no idioma pedido
+func setBreadcrumbs(products []*model.Product, locale string) {
+	if len(products) == 0 {
+		return
+	}
+	categories, err := GetCategories(locale)
+	if err != nil {
+		log.Printf("Erro ao montar breadcrumbs: %v", err)
+		return
+	}
+	idx := newCategoryIndex(categories)
+	for _, p := range products {
+		p.Breadcrumbs = idx.path(p.CategoryID)
+	}
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
+	"gorm.io/gorm"
+)
+
+// MaxCouponItems limita a quantidade de itens conferidos numa validação de cupom
+const MaxCouponItems = 100
+
+var couponCodeRegex = regexp.MustCompile(`^[A-Z0-9_-]{3,40}$`)
+
+var (
+	// ErrCouponNotFound indica que não existe cupom com o código ou ID pedido
+	ErrCouponNotFound = errors.New("cupom não encontrado")
+	// ErrCouponInvalid indica que o cupom existe mas não pode ser usado neste pedido; o motivo vem junto
+	ErrCouponInvalid = errors.New("cupom inválido")
+	// ErrCouponOrderInvalid indica itens do pedido inválidos (produto inexistente, sem preço, quantidade zero...)
+	ErrCouponOrderInvalid = errors.New("pedido inválido")
+)
+
+// CouponItem é um item do pedido conferido pelo cupom; UnitPrice escolhe um valor dentro da faixa de preço do produto
+type CouponItem struct {
+	ProductID uint     `json:"productId"`
+	Quantity  int      `json:"quantity"`
+	UnitPrice *float64 `json:"unitPrice"`
+}
+
+// CouponQuoteItem é um item do pedido com o preço considerado e se ele entra no desconto
+type CouponQuoteItem struct {
+	ProductID uint    `json:"productId"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unitPrice"`
+	Total     float64 `json:"total"`
+	Eligible  bool    `json:"eligible"`
+
+	categoryID uint // categoria do produto, para conferir o escopo do cupom
+}
+
+// CouponQuote é o resultado da aplicação de um cupom a um pedido, em reais
+type CouponQuote struct {
+	Code             string            `json:"code"`
+	Type             model.CouponType  `json:"type"`
+	Value            float64           `json:"value"`
+	Items            []CouponQuoteItem `json:"items"`
+	Subtotal         float64           `json:"subtotal"`
+	EligibleSubtotal float64           `json:"eligibleSubtotal"`
+	Discount         float64           `json:"discount"`
+	Total            float64           `json:"total"`
+}
+
+// NormalizeCouponCode padroniza o código digitado: sem espaços nas pontas e em maiúsculas
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// roundCents arredonda um valor em reais para centavos
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// validateCoupon normaliza e confere os dados de um cupom; couponID é 0 para cupons novos
+func validateCoupon(couponID uint, coupon *model.Coupon) error {
+	coupon.Code = NormalizeCouponCode(coupon.Code)
+	if !couponCodeRegex.MatchString(coupon.Code) {
+		return errors.New("code deve ter de 3 a 40 caracteres entre letras, números, - e _")
+	}
+	if !coupon.Type.IsValid() {
+		return errors.New("type inválido: use percentage ou fixed")
+	}
+	if coupon.Value <= 0 {
+		return errors.New("value deve ser maior que zero")
+	}
+	if coupon.Type == model.CouponPercentage && coupon.Value > 100 {
+		return errors.New("value de cupom percentual deve ser no máximo 100")
+	}
+	if coupon.StartAt != nil && coupon.EndAt != nil && coupon.EndAt.Before(*coupon.StartAt) {
+		return errors.New("endAt deve ser maior ou igual a startAt")
+	}
+	if coupon.MaxRedemptions < 0 || coupon.MaxRedemptionsPerUser < 0 {
+		return errors.New("limites de uso não podem ser negativos")
+	}
+	if coupon.MinOrderValue < 0 {
+		return errors.New("minOrderValue não pode ser negativo")
+	}
+
+	coupon.CategoryIDs = uniqueIDs(coupon.CategoryIDs)
+	if len(coupon.CategoryIDs) > 0 {
+		categories, err := repository.GetCategories()
+		if err != nil {
+			return err
+		}
+		idx := newCategoryIndex(categories)
+		for _, id := range coupon.CategoryIDs {
+			if idx.byID[id] == nil {
+				return fmt.Errorf("categoria %d não encontrada", id)
+			}
+		}
+	}
+
+	existing, err := repository.GetCouponByCode(coupon.Code)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != couponID {
+		return errors.New("já existe um cupom com este código")
+	}
+	return nil
+}
+
+// GetCoupons lista todos os cupons (admin)
+func GetCoupons() ([]model.Coupon, error) {
+	return repository.GetCoupons()
+}
+
+// GetCoupon retorna um cupom pelo ID (admin)
+func GetCoupon(couponID uint) (*model.Coupon, error) {
+	coupon, err := repository.GetCouponByID(couponID)
+	if err != nil {
+		return nil, err
+	}
+	if coupon == nil {
+		return nil, ErrCouponNotFound
+	}
+	return coupon, nil
+}
+
+// CreateCoupon cadastra um novo cupom (admin)
+func CreateCoupon(coupon *model.Coupon) error {
+	coupon.ID = 0
+	coupon.RedemptionCount = 0
+	if err := validateCoupon(0, coupon); err != nil {
+		return err
+	}
+	if err := repository.CreateCoupon(coupon); err != nil {
+		return fmt.Errorf("erro ao criar cupom: %w", err)
+	}
+	return nil
+}
+
+// UpdateCoupon substitui os dados de um cupom, mantendo a contagem de usos (admin)
+func UpdateCoupon(couponID uint, coupon *model.Coupon) error {
+	existing, err := repository.GetCouponByID(couponID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrCouponNotFound
+	}
+	if err := validateCoupon(couponID, coupon); err != nil {
+		return err
+	}
+
+	coupon.ID = existing.ID
+	coupon.CreatedAt = existing.CreatedAt
+	coupon.RedemptionCount = existing.RedemptionCount
+	if err := repository.UpdateCoupon(coupon); err != nil {
+		return fmt.Errorf("erro ao atualizar cupom: %w", err)
+	}
+	return nil
+}
+
+// DeleteCoupon deleta um cupom; os usos já registrados são mantidos (admin)
+func DeleteCoupon(couponID uint) error {
+	deleted, err := repository.DeleteCoupon(couponID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrCouponNotFound
+	}
+	return nil
+}
+
+// GetCouponRedemptions lista os usos de um cupom (admin)
+func GetCouponRedemptions(couponID uint) ([]model.CouponRedemption, error) {
+	if _, err := GetCoupon(couponID); err != nil {
+		return nil, err
+	}
+	return repository.GetCouponRedemptions(couponID)
+}
+
+/**
+ * priceCouponItems resolves the unit price of each item from the product's
+ * price range (its lowest amount, or UnitPrice when it falls inside the
+ * range) and applies the running promotion discount, if any. Only products
+ * visible on the storefront are accepted.
+ */
+func priceCouponItems(items []CouponItem) ([]CouponQuoteItem, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: informe ao menos um item", ErrCouponOrderInvalid)
+	}
+	if len(items) > MaxCouponItems {
+		return nil, fmt.Errorf("%w: no máximo %d itens por pedido", ErrCouponOrderInvalid, MaxCouponItems)
+	}
+
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		if item.Quantity < 1 {
+			return nil, fmt.Errorf("%w: quantidade inválida para o produto %d", ErrCouponOrderInvalid, item.ProductID)
+		}
+		ids = append(ids, item.ProductID)
+	}
+	products, err := repository.GetVisibleProductsByIDs(uniqueIDs(ids))
+	if err != nil {
+		return nil, err
+	}
+	ApplyPromotionDiscounts(products)
+	byID := make(map[uint]*model.Product, len(products))
+	for i := range products {
+		byID[products[i].ID] = &products[i]
+	}
+
+	quoted := make([]CouponQuoteItem, 0, len(items))
+	for _, item := range items {
+		product := byID[item.ProductID]
+		if product == nil {
+			return nil, fmt.Errorf("%w: produto %d não encontrado", ErrCouponOrderInvalid, item.ProductID)
+		}
+		min, max, ok := util.ParsePriceRange(product.PriceRange)
+		if !ok {
+			return nil, fmt.Errorf("%w: produto %d não tem preço definido", ErrCouponOrderInvalid, item.ProductID)
+		}
+
+		unitPrice := min
+		if item.UnitPrice != nil {
+			if *item.UnitPrice < min || *item.UnitPrice > max {
+				return nil, fmt.Errorf("%w: unitPrice do produto %d deve estar entre %s e %s", ErrCouponOrderInvalid, item.ProductID, util.FormatBRL(min, true), util.FormatBRL(max, true))
+			}
+			unitPrice = *item.UnitPrice
+		}
+		if product.Discount != nil {
+			unitPrice = unitPrice * (1 - float64(product.Discount.Percentage)/100)
+		}
+		unitPrice = roundCents(unitPrice)
+
+		quoted = append(quoted, CouponQuoteItem{
+			ProductID:  product.ID,
+			Name:       product.Name,
+			Quantity:   item.Quantity,
+			UnitPrice:  unitPrice,
+			Total:      roundCents(unitPrice * float64(item.Quantity)),
+			categoryID: product.CategoryID,
+		})
+	}
+	return quoted, nil
+}
+
+/**
+ * quoteCoupon checks a code against an order and calculates the discount.
+ * Problems with the order itself are plain errors; reasons for the coupon not
+ * applying wrap ErrCouponInvalid. The usage limits are checked here for a
+ * quick answer and again, under a row lock, when the coupon is redeemed.
+ *
+ * @param code - The code typed by the customer
+ * @param userID - The logged in customer, for the per-user limit
+ * @param items - The order items
+ */
+func quoteCoupon(code string, userID uint, items []CouponItem) (*model.Coupon, *CouponQuote, error) {
+	coupon, err := repository.GetCouponByCode(NormalizeCouponCode(code))
+	if err != nil {
+		return nil, nil, err
+	}
+	if coupon == nil {
+		return nil, nil, ErrCouponNotFound
+	}
+
+	if err := repository.CouponAvailability(coupon, time.Now().UTC()); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrCouponInvalid, err)
+	}
+	if coupon.MaxRedemptions > 0 && coupon.RedemptionCount >= coupon.MaxRedemptions {
+		return nil, nil, fmt.Errorf("%w: %v", ErrCouponInvalid, repository.ErrCouponExhausted)
+	}
+	if coupon.MaxRedemptionsPerUser > 0 {
+		used, err := repository.CountUserCouponRedemptions(coupon.ID, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if used >= int64(coupon.MaxRedemptionsPerUser) {
+			return nil, nil, fmt.Errorf("%w: %v", ErrCouponInvalid, repository.ErrCouponUserLimit)
+		}
+	}
+
+	quoted, err := priceCouponItems(items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quote, err := applyCoupon(coupon, quoted)
+	if err != nil {
+		return nil, nil, err
+	}
+	return coupon, quote, nil
+}
+
+/**
+ * applyCoupon calculates the discount of a coupon over priced order items:
+ * category scope, minimum order and the discount itself. Failures wrap
+ * ErrCouponInvalid.
+ *
+ * @param coupon - The coupon as currently stored
+ * @param quoted - The priced items; they are copied, not changed
+ */
+func applyCoupon(coupon *model.Coupon, quoted []CouponQuoteItem) (*CouponQuote, error) {
+	// Sem categorias, todos os itens entram no desconto; com categorias, vale também para as subcategorias
+	var inScope map[uint]bool
+	if len(coupon.CategoryIDs) > 0 {
+		categories, err := repository.GetCategories()
+		if err != nil {
+			return nil, err
+		}
+		idx := newCategoryIndex(categories)
+		inScope = map[uint]bool{}
+		for _, id := range coupon.CategoryIDs {
+			for _, descendant := range idx.descendants(id) {
+				inScope[descendant] = true
+			}
+		}
+	}
+
+	quote := &CouponQuote{Code: coupon.Code, Type: coupon.Type, Value: coupon.Value, Items: append([]CouponQuoteItem(nil), quoted...)}
+	for i := range quote.Items {
+		item := &quote.Items[i]
+		item.Eligible = inScope == nil || inScope[item.categoryID]
+		quote.Subtotal += item.Total
+		if item.Eligible {
+			quote.EligibleSubtotal += item.Total
+		}
+	}
+	quote.Subtotal = roundCents(quote.Subtotal)
+	quote.EligibleSubtotal = roundCents(quote.EligibleSubtotal)
+
+	if quote.Subtotal < coupon.MinOrderValue {
+		return nil, fmt.Errorf("%w: pedido mínimo de R$ %s", ErrCouponInvalid, util.FormatBRL(coupon.MinOrderValue, true))
+	}
+	if quote.EligibleSubtotal == 0 {
+		return nil, fmt.Errorf("%w: nenhum item do pedido está nas categorias do cupom", ErrCouponInvalid)
+	}
+
+	if coupon.Type == model.CouponPercentage {
+		quote.Discount = roundCents(quote.EligibleSubtotal * coupon.Value / 100)
+	} else {
+		quote.Discount = math.Min(coupon.Value, quote.EligibleSubtotal)
+	}
+	quote.Total = roundCents(quote.Subtotal - quote.Discount)
+
+	return quote, nil
+}
+
+// ValidateCoupon confere um código contra os itens do pedido e calcula o desconto, sem registrar uso
+func ValidateCoupon(code string, userID uint, items []CouponItem) (*CouponQuote, error) {
+	_, quote, err := quoteCoupon(code, userID, items)
+	return quote, err
+}
+
+/**
+ * RedeemCoupon validates the code against the order and records its use.
+ * Several requests redeeming the same code at once are serialized by the
+ * repository, so the usage limits are never exceeded. The discount is
+ * calculated again from the locked coupon, so an edit made after the first
+ * check is never recorded with the old values.
+ *
+ * @param code - The code typed by the customer
+ * @param userID - The logged in customer
+ * @param items - The order items
+ */
+func RedeemCoupon(code string, userID uint, items []CouponItem) (*CouponQuote, error) {
+	coupon, quote, err := quoteCoupon(code, userID, items)
+	if err != nil {
+		return nil, err
+	}
+
+	redemption := &model.CouponRedemption{
+		CouponID: coupon.ID,
+		UserID:   userID,
+	}
+	err = repository.RedeemCoupon(redemption, func(locked *model.Coupon) error {
+		current, err := applyCoupon(locked, quote.Items)
+		if err != nil {
+			return err
+		}
+		quote = current
+		redemption.Subtotal = quote.Subtotal
+		redemption.Discount = quote.Discount
+		return nil
+	})
+	switch {
+	case errors.Is(err, ErrCouponInvalid):
+		return nil, err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, ErrCouponNotFound
+	case errors.Is(err, repository.ErrCouponDisabled), errors.Is(err, repository.ErrCouponNotStarted),
+		errors.Is(err, repository.ErrCouponExpired), errors.Is(err, repository.ErrCouponExhausted),
+		errors.Is(err, repository.ErrCouponUserLimit):
+		return nil, fmt.Errorf("%w: %v", ErrCouponInvalid, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao registrar uso do cupom: %w", err)
+	}
+	return quote, nil
+}
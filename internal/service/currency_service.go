@@ -0,0 +1,344 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
+)
+
+// DefaultCurrencyRoundingStep arredonda os preços convertidos para centavos
+const DefaultCurrencyRoundingStep = 0.01
+
+// MaxExchangeRateImportRows limita o tamanho de um arquivo de cotações
+const MaxExchangeRateImportRows = 5000
+
+var (
+	// ErrExchangeRateNotFound indica que a moeda ainda não tem cotação em vigor
+	ErrExchangeRateNotFound = errors.New("nenhuma cotação em vigor")
+	// ErrUnsupportedCurrency indica uma moeda fora de SupportedCurrencies
+	ErrUnsupportedCurrency = errors.New("moeda não suportada")
+)
+
+// exchangeRateDateLayouts são os formatos de data aceitos no cadastro e na importação
+var exchangeRateDateLayouts = []string{"2006-01-02", "02/01/2006"}
+
+// ExchangeRateInput é o payload de cadastro de uma cotação
+type ExchangeRateInput struct {
+	Currency      string  `json:"currency" binding:"required"`
+	Rate          float64 `json:"rate" binding:"required"`
+	EffectiveDate string  `json:"effectiveDate" binding:"required"` // AAAA-MM-DD
+}
+
+// ExchangeRateImportReport é o relatório devolvido pela importação de cotações
+type ExchangeRateImportReport struct {
+	DryRun    bool                 `json:"dryRun"`
+	Imported  bool                 `json:"imported"`
+	TotalRows int                  `json:"totalRows"`
+	Rates     []model.ExchangeRate `json:"rates"`
+	Errors    []ImportRowError     `json:"errors"`
+}
+
+// currencyRounding lê o passo e o modo de arredondamento dos preços convertidos
+func currencyRounding() (float64, string) {
+	step := DefaultCurrencyRoundingStep
+	if v, err := strconv.ParseFloat(os.Getenv("CURRENCY_ROUNDING_STEP"), 64); err == nil && v > 0 {
+		step = v
+	}
+	mode := strings.ToLower(os.Getenv("CURRENCY_ROUNDING_MODE"))
+	if mode != "up" && mode != "down" {
+		mode = "nearest"
+	}
+	return step, mode
+}
+
+// today retorna a data atual (UTC) usada para escolher a cotação em vigor
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// parseExchangeRateDate converte a data de vigência, em AAAA-MM-DD ou DD/MM/AAAA
+func parseExchangeRateDate(value string) (time.Time, error) {
+	for _, layout := range exchangeRateDateLayouts {
+		if date, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("data %q inválida (use AAAA-MM-DD)", value)
+}
+
+// validateExchangeRate normaliza a moeda e confere os valores de uma cotação
+func validateExchangeRate(rate *model.ExchangeRate) error {
+	rate.Currency = strings.ToUpper(strings.TrimSpace(rate.Currency))
+	if rate.Currency == model.BaseCurrency {
+		return fmt.Errorf("%s é a moeda base e não precisa de cotação", model.BaseCurrency)
+	}
+	if !model.IsSupportedCurrency(rate.Currency) {
+		return fmt.Errorf("moeda %q não suportada (use %s)", rate.Currency, strings.Join(model.SupportedCurrencies[1:], ", "))
+	}
+	if rate.Rate <= 0 || math.IsInf(rate.Rate, 0) || math.IsNaN(rate.Rate) {
+		return errors.New("a cotação deve ser maior que zero")
+	}
+	return nil
+}
+
+// SaveExchangeRate cadastra a cotação de uma moeda a partir de uma data, substituindo a do mesmo dia
+func SaveExchangeRate(input ExchangeRateInput) (*model.ExchangeRate, error) {
+	date, err := parseExchangeRateDate(input.EffectiveDate)
+	if err != nil {
+		return nil, err
+	}
+	rate := &model.ExchangeRate{Currency: input.Currency, Rate: input.Rate, EffectiveDate: date, Source: "manual"}
+	if err := validateExchangeRate(rate); err != nil {
+		return nil, err
+	}
+	if err := repository.SaveExchangeRate(rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// GetExchangeRates lista o histórico de cotações, opcionalmente de uma moeda
+func GetExchangeRates(currency string) ([]model.ExchangeRate, error) {
+	return repository.GetExchangeRates(strings.ToUpper(currency))
+}
+
+// DeleteExchangeRate remove a cotação de uma moeda em uma data
+func DeleteExchangeRate(currency, effectiveDate string) error {
+	date, err := parseExchangeRateDate(effectiveDate)
+	if err != nil {
+		return err
+	}
+	deleted, err := repository.DeleteExchangeRate(strings.ToUpper(currency), date)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("cotação não encontrada")
+	}
+	return nil
+}
+
+// GetCurrentExchangeRates retorna a cotação em vigor de cada moeda que já tem uma
+func GetCurrentExchangeRates() ([]model.ExchangeRate, error) {
+	rates := []model.ExchangeRate{}
+	for _, currency := range model.SupportedCurrencies[1:] {
+		rate, err := repository.GetEffectiveExchangeRate(currency, today())
+		if err != nil {
+			return nil, err
+		}
+		if rate != nil {
+			rates = append(rates, *rate)
+		}
+	}
+	return rates, nil
+}
+
+/**
+ * GetCurrentExchangeRate returns the rate in effect today for a currency.
+ * The base currency needs no conversion and gives nil.
+ *
+ * @param currency - The ISO code asked in ?currency=, case insensitive
+ * @returns - ErrExchangeRateNotFound when no rate is in effect yet
+ */
+func GetCurrentExchangeRate(currency string) (*model.ExchangeRate, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" || currency == model.BaseCurrency {
+		return nil, nil
+	}
+	if !model.IsSupportedCurrency(currency) {
+		return nil, fmt.Errorf("%w: %q (use %s)", ErrUnsupportedCurrency, currency, strings.Join(model.SupportedCurrencies, ", "))
+	}
+
+	rate, err := repository.GetEffectiveExchangeRate(currency, today())
+	if err != nil {
+		return nil, err
+	}
+	if rate == nil {
+		return nil, fmt.Errorf("%w para %s", ErrExchangeRateNotFound, currency)
+	}
+	return rate, nil
+}
+
+/**
+ * ConvertPrice converts the amounts of a free-form BRL price with the given
+ * rate, rounding them as configured by CURRENCY_ROUNDING_STEP and
+ * CURRENCY_ROUNDING_MODE.
+ *
+ * @param priceRange - The price text as stored in Product.PriceRange
+ * @param rate - The exchange rate to apply
+ * @returns - nil when the text has no amount, e.g. "Sob consulta"
+ */
+func ConvertPrice(priceRange string, rate *model.ExchangeRate) *model.ConvertedPrice {
+	min, max, ok := util.ParsePriceRange(priceRange)
+	if !ok {
+		return nil
+	}
+
+	step, mode := currencyRounding()
+	decimals := 2
+	if step >= 1 && step == math.Trunc(step) {
+		decimals = 0
+	}
+
+	price := &model.ConvertedPrice{
+		Currency:         rate.Currency,
+		Min:              util.RoundToStep(min/rate.Rate, step, mode),
+		Max:              util.RoundToStep(max/rate.Rate, step, mode),
+		OriginalCurrency: model.BaseCurrency,
+		OriginalMin:      min,
+		OriginalMax:      max,
+		OriginalText:     priceRange,
+		Rate:             rate.Rate,
+		RateDate:         rate.EffectiveDate,
+	}
+	symbol := model.CurrencySymbols[rate.Currency]
+	price.Text = symbol + " " + util.FormatAmount(price.Min, decimals)
+	if price.Max != price.Min {
+		price.Text += " - " + symbol + " " + util.FormatAmount(price.Max, decimals)
+	}
+	return price
+}
+
+// ApplyExchangeRate preenche o preço convertido dos produtos, inclusive o com desconto; sem cotação (moeda base) não faz nada
+func ApplyExchangeRate(products []model.Product, rate *model.ExchangeRate) {
+	if rate == nil {
+		return
+	}
+	for i := range products {
+		products[i].Price = ConvertPrice(products[i].PriceRange, rate)
+		if products[i].Price == nil || products[i].Discount == nil {
+			continue
+		}
+		if discounted := ConvertPrice(products[i].Discount.Text, rate); discounted != nil {
+			products[i].Price.DiscountedMin = discounted.Min
+			products[i].Price.DiscountedMax = discounted.Max
+			products[i].Price.DiscountedText = discounted.Text
+		}
+	}
+}
+
+/**
+ * ImportExchangeRatesCSV reads rates from a CSV with the columns currency,
+ * rate and date (header required, comma or semicolon separated) and, unless
+ * dryRun is set, saves them in a single transaction. Rates may use a decimal
+ * comma. Nothing is written when any row has errors.
+ *
+ * @param r - The CSV content
+ * @param dryRun - Only validate the file
+ */
+func ImportExchangeRatesCSV(r io.Reader, dryRun bool) (*ExchangeRateImportReport, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	firstLine, _, _ := strings.Cut(text, "\n")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("arquivo CSV vazio")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler cabeçalho do CSV: %w", err)
+	}
+	columns := map[string]int{}
+	for i, h := range header {
+		switch strings.ToLower(strings.TrimSpace(h)) {
+		case "currency", "moeda":
+			columns["currency"] = i
+		case "rate", "cotacao", "cotação":
+			columns["rate"] = i
+		case "date", "effectivedate", "effective_date", "data":
+			columns["date"] = i
+		}
+	}
+	for _, required := range []string{"currency", "rate", "date"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("o CSV precisa das colunas currency, rate e date (faltou %s)", required)
+		}
+	}
+
+	report := &ExchangeRateImportReport{DryRun: dryRun, Rates: []model.ExchangeRate{}, Errors: []ImportRowError{}}
+	seen := map[string]int{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler linha %d do CSV: %w", line, err)
+		}
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if field("currency") == "" && field("rate") == "" && field("date") == "" {
+			continue
+		}
+		report.TotalRows++
+		if report.TotalRows > MaxExchangeRateImportRows {
+			return nil, fmt.Errorf("o arquivo excede o limite de %d linhas", MaxExchangeRateImportRows)
+		}
+
+		addError := func(name, message string) {
+			report.Errors = append(report.Errors, ImportRowError{Row: line, Field: name, Message: message})
+		}
+		rowErrors := len(report.Errors)
+
+		rate := model.ExchangeRate{Currency: field("currency"), Source: "import"}
+		value, convErr := strconv.ParseFloat(strings.ReplaceAll(field("rate"), ",", "."), 64)
+		if convErr != nil {
+			addError("rate", fmt.Sprintf("cotação %q inválida", field("rate")))
+		}
+		rate.Rate = value
+		date, dateErr := parseExchangeRateDate(field("date"))
+		if dateErr != nil {
+			addError("date", dateErr.Error())
+		}
+		rate.EffectiveDate = date
+		if convErr == nil {
+			if err := validateExchangeRate(&rate); err != nil {
+				addError("", err.Error())
+			}
+		}
+
+		if len(report.Errors) > rowErrors {
+			continue
+		}
+		key := rate.Currency + ":" + date.Format("2006-01-02")
+		if first, dup := seen[key]; dup {
+			addError("date", fmt.Sprintf("cotação repetida no arquivo (linha %d)", first))
+			continue
+		}
+		seen[key] = line
+		report.Rates = append(report.Rates, rate)
+	}
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+	if err := repository.SaveExchangeRates(report.Rates); err != nil {
+		return nil, err
+	}
+	report.Imported = true
+	return report, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+)
+
+// AddFavorite adiciona um produto visível na vitrine aos favoritos do usuário
+func AddFavorite(userID, productID uint) error {
+	product, err := repository.GetVisibleProductByID(productID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return errors.New("produto não encontrado")
+	}
+	return repository.AddFavorite(userID, productID)
+}
+
+// RemoveFavorite remove um produto dos favoritos do usuário
+func RemoveFavorite(userID, productID uint) error {
+	return repository.RemoveFavorite(userID, productID)
+}
+
+// GetUserFavorites retorna os favoritos do usuário, paginados e no idioma pedido
+func GetUserFavorites(userID uint, page, limit int, locale string) (*model.PaginatedResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	products, total, err := repository.GetUserFavoritesWithCount(userID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+	localizeProducts(products, locale)
+
+	return &model.PaginatedResponse{
+		Data:     products,
+		Metadata: model.CalculatePagination(page, limit, total),
+	}, nil
+}
+
+// GetMostFavoritedReport retorna os produtos mais favoritados para o painel admin
+func GetMostFavoritedReport(limit int) ([]model.FavoriteReportItem, error) {
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return repository.GetMostFavorited(limit)
+}
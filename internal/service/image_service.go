@@ -0,0 +1,43 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+)
+
+// uploadsDir é o diretório servido em /uploads para imagens armazenadas localmente
+const uploadsDir = "./uploads"
+
+/**
+ * deleteImageFileIfUnused removes a locally stored image once no product or
+ * category references it anymore. Remote images (ImgBB) are never deleted
+ * here, since the files are shared by URL and managed by the host.
+ *
+ * @param imagePath - The path or URL that was just unreferenced
+ * @returns - Whether the file was removed
+ */
+func deleteImageFileIfUnused(imagePath string) (bool, error) {
+	if imagePath == "" || strings.HasPrefix(imagePath, "http://") || strings.HasPrefix(imagePath, "https://") {
+		return false, nil
+	}
+
+	refs, err := repository.CountImageReferences(imagePath)
+	if err != nil {
+		return false, err
+	}
+	if refs > 0 {
+		return false, nil
+	}
+
+	relative := filepath.Clean("/" + strings.TrimPrefix(imagePath, "/uploads"))
+	if err := os.Remove(filepath.Join(uploadsDir, relative)); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
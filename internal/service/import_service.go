@@ -0,0 +1,423 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+)
+
+// MaxImportRows limita o tamanho de um arquivo de importação
+const MaxImportRows = 5000
+
+// ImportOptions controla o comportamento da importação de produtos
+type ImportOptions struct {
+	DryRun           bool
+	CreateCategories bool
+}
+
+// ImportRowError descreve um problema de validação em uma linha do CSV
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportRowResult descreve o que será (ou foi) feito com uma linha válida
+type ImportRowResult struct {
+	Row       int    `json:"row"`
+	Name      string `json:"name"`
+	Action    string `json:"action"` // create ou update
+	ProductID uint   `json:"productId,omitempty"`
+}
+
+// ImportReport é o relatório devolvido pela importação
+type ImportReport struct {
+	DryRun            bool              `json:"dryRun"`
+	Imported          bool              `json:"imported"`
+	TotalRows         int               `json:"totalRows"`
+	Created           int               `json:"created"`
+	Updated           int               `json:"updated"`
+	CategoriesCreated []string          `json:"categoriesCreated"`
+	Rows              []ImportRowResult `json:"rows"`
+	Errors            []ImportRowError  `json:"errors"`
+}
+
+// importColumns mapeia os nomes de coluna aceitos para o campo correspondente
+var importColumns = map[string]string{
+	"name":        "name",
+	"nome":        "name",
+	"description": "description",
+	"descricao":   "description",
+	"price":       "price",
+	"preco":       "price",
+	"category":    "category",
+	"categoria":   "category",
+	"categoryid":  "category",
+	"imageurls":   "imageUrls",
+	"images":      "imageUrls",
+	"imagens":     "imageUrls",
+	"status":      "status",
+}
+
+type importRow struct {
+	line   int
+	fields map[string]string
+}
+
+/**
+ * ImportProductsCSV validates a CSV catalog and, unless DryRun is set, upserts
+ * its products in a single transaction. Products are matched by name without
+ * regard to case; new products start as drafts unless a status column says
+ * otherwise. Nothing is written when any row has errors.
+ *
+ * Expected columns: name, description, price, category (name or ID),
+ * imageUrls (separated by "|") and an optional status.
+ *
+ * @param r - The CSV content (comma or semicolon separated, with header)
+ * @param opts - Dry run and category creation options
+ */
+func ImportProductsCSV(r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	rows, err := readImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{
+		DryRun:            opts.DryRun,
+		TotalRows:         len(rows),
+		CategoriesCreated: []string{},
+		Rows:              []ImportRowResult{},
+		Errors:            []ImportRowError{},
+	}
+
+	categories, err := repository.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	categoriesByID := make(map[uint]*model.Category, len(categories))
+	categoriesByName := make(map[string]*model.Category, len(categories))
+	for i := range categories {
+		categoriesByID[categories[i].ID] = &categories[i]
+		categoriesByName[strings.ToLower(categories[i].Name)] = &categories[i]
+	}
+
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, strings.ToLower(strings.TrimSpace(row.fields["name"])))
+	}
+	existing, err := repository.GetProductsByLowerNames(names)
+	if err != nil {
+		return nil, err
+	}
+	existingByName := make(map[string]model.Product, len(existing))
+	for _, p := range existing {
+		if _, ok := existingByName[strings.ToLower(p.Name)]; !ok {
+			existingByName[strings.ToLower(p.Name)] = p
+		}
+	}
+
+	newCategories := map[string]*model.Category{}
+	var newCategoryList []*model.Category
+	seenNames := map[string]int{}
+	categorySlugs := map[string]bool{}
+	productSlugs := map[string]bool{}
+	var items []repository.ProductImportItem
+
+	// Produtos que entram numa categoria vão para o final da ordem manual, contando os que o próprio arquivo adiciona
+	productPositions := map[uint]int{}
+	newCategoryPositions := map[*model.Category]int{}
+	nextProductPosition := func(categoryID uint, newCategory *model.Category) (int, error) {
+		if newCategory != nil {
+			newCategoryPositions[newCategory]++
+			return newCategoryPositions[newCategory], nil
+		}
+		if position, ok := productPositions[categoryID]; ok {
+			productPositions[categoryID] = position + 1
+			return position + 1, nil
+		}
+		position, err := repository.NextProductPosition(categoryID)
+		if err != nil {
+			return 0, fmt.Errorf("erro ao calcular posição: %w", err)
+		}
+		productPositions[categoryID] = position
+		return position, nil
+	}
+	nextCategoryPosition := 0
+
+	for _, row := range rows {
+		addError := func(field, message string) {
+			report.Errors = append(report.Errors, ImportRowError{Row: row.line, Field: field, Message: message})
+		}
+		rowErrors := len(report.Errors)
+
+		name := strings.TrimSpace(row.fields["name"])
+		price := strings.TrimSpace(row.fields["price"])
+		categoryRef := strings.TrimSpace(row.fields["category"])
+		status := model.ProductStatus(strings.ToLower(strings.TrimSpace(row.fields["status"])))
+
+		if name == "" {
+			addError("name", "nome é obrigatório")
+		} else if first, dup := seenNames[strings.ToLower(name)]; dup {
+			addError("name", fmt.Sprintf("produto repetido no arquivo (linha %d)", first))
+		} else {
+			seenNames[strings.ToLower(name)] = row.line
+		}
+		if price == "" {
+			addError("price", "preço é obrigatório")
+		}
+		if status != "" && !status.IsValid() {
+			addError("status", "status inválido: use draft, published ou archived")
+		}
+
+		imageUrls, badURL := parseImportImageUrls(row.fields["imageUrls"])
+		if badURL != "" {
+			addError("imageUrls", "URL de imagem inválida: "+badURL)
+		}
+
+		var category *model.Category
+		var newCategory *model.Category
+		switch {
+		case categoryRef == "":
+			addError("category", "categoria é obrigatória")
+		default:
+			if id, convErr := strconv.ParseUint(categoryRef, 10, 64); convErr == nil {
+				category = categoriesByID[uint(id)]
+				if category == nil {
+					addError("category", fmt.Sprintf("categoria %d não encontrada", id))
+				}
+				break
+			}
+
+			key := strings.ToLower(categoryRef)
+			if category = categoriesByName[key]; category != nil {
+				break
+			}
+			if !opts.CreateCategories {
+				addError("category", fmt.Sprintf("categoria %q não existe", categoryRef))
+				break
+			}
+			if newCategory = newCategories[key]; newCategory == nil {
+				slug, slugErr := generateUniqueSlugReserving(model.SlugEntityCategory, categoryRef, 0, categorySlugs)
+				if slugErr != nil {
+					return nil, slugErr
+				}
+				// Categorias criadas pela importação são raízes e entram no final da ordem manual
+				if nextCategoryPosition == 0 {
+					if nextCategoryPosition, err = repository.NextCategoryPosition(nil); err != nil {
+						return nil, fmt.Errorf("erro ao calcular posição: %w", err)
+					}
+				} else {
+					nextCategoryPosition++
+				}
+				newCategory = &model.Category{Name: categoryRef, Slug: slug, Position: nextCategoryPosition}
+				newCategories[key] = newCategory
+				newCategoryList = append(newCategoryList, newCategory)
+				report.CategoriesCreated = append(report.CategoriesCreated, categoryRef)
+			}
+		}
+
+		current, exists := existingByName[strings.ToLower(name)]
+		// Produtos novos precisam preencher os atributos obrigatórios da categoria, que o CSV não traz
+		if !exists && category != nil {
+			if _, attrErr := category.AttributeSchema.ValidateValues(nil, true); attrErr != nil {
+				addError("category", attrErr.Error())
+			}
+		}
+
+		if len(report.Errors) > rowErrors {
+			continue
+		}
+
+		item := repository.ProductImportItem{NewCategory: newCategory}
+		result := ImportRowResult{Row: row.line, Name: name}
+
+		if exists {
+			previous := model.SnapshotOf(&current)
+			product := current
+			product.Name = name
+			product.PriceRange = price
+			if description, ok := row.fields["description"]; ok {
+				product.Description = description
+			}
+			// Células de imagem vazias mantêm as imagens já cadastradas
+			if imageUrls != "" {
+				product.ImageUrls = imageUrls
+			}
+			if status != "" {
+				product.Status = status
+			}
+			// Ao trocar de categoria, o produto vai para o final da ordem manual e perde os atributos fora do novo esquema
+			if newCategory != nil || category.ID != current.CategoryID {
+				var schema model.AttributeSchema
+				if category != nil {
+					product.CategoryID = category.ID
+					schema = category.AttributeSchema
+				}
+				if product.Position, err = nextProductPosition(product.CategoryID, newCategory); err != nil {
+					return nil, err
+				}
+				product.Attributes = schema.Prune(current.Attributes)
+			}
+			item.Product = &product
+			item.Previous = &previous
+			result.Action = "update"
+			result.ProductID = current.ID
+			report.Updated++
+		} else {
+			slug, slugErr := generateUniqueSlugReserving(model.SlugEntityProduct, name, 0, productSlugs)
+			if slugErr != nil {
+				return nil, slugErr
+			}
+			if status == "" {
+				status = model.ProductStatusDraft
+			}
+			product := model.Product{
+				Name:         name,
+				Slug:         slug,
+				Description:  row.fields["description"],
+				PriceRange:   price,
+				ImageUrls:    imageUrls,
+				Status:       status,
+				Availability: model.AvailabilityMadeToOrder,
+			}
+			if category != nil {
+				product.CategoryID = category.ID
+			}
+			if product.Position, err = nextProductPosition(product.CategoryID, newCategory); err != nil {
+				return nil, err
+			}
+			item.Product = &product
+			result.Action = "create"
+			report.Created++
+		}
+
+		items = append(items, item)
+		report.Rows = append(report.Rows, result)
+	}
+
+	if opts.DryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	if err := repository.ImportProducts(newCategoryList, items); err != nil {
+		return nil, fmt.Errorf("erro ao importar produtos: %w", err)
+	}
+
+	for i, item := range items {
+		report.Rows[i].ProductID = item.Product.ID
+		if item.Previous != nil {
+			if err := repository.PruneProductRevisions(item.Product.ID, revisionRetention()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	report.Imported = true
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+	if len(newCategoryList) > 0 {
+		cacheService.InvalidateCategoryCache()
+	}
+
+	return report, nil
+}
+
+/**
+ * readImportCSV parses the CSV header and rows. The delimiter is detected from
+ * the header line so files exported by spreadsheets in pt-BR (";") also work.
+ */
+func readImportCSV(r io.Reader) ([]importRow, error) {
+	buffered := bufio.NewReader(r)
+	headerLine, err := buffered.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	firstLine := string(headerLine)
+	if i := strings.IndexAny(firstLine, "\r\n"); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("arquivo CSV vazio")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler cabeçalho do CSV: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	hasName := false
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		key = strings.NewReplacer("_", "", " ", "", "ç", "c", "ã", "a").Replace(key)
+		columns[i] = importColumns[key]
+		if columns[i] == "name" {
+			hasName = true
+		}
+	}
+	if !hasName {
+		return nil, errors.New("o CSV precisa de uma coluna name")
+	}
+
+	var rows []importRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler linha %d do CSV: %w", line, err)
+		}
+
+		fields := make(map[string]string, len(columns))
+		empty := true
+		for i, value := range record {
+			if i < len(columns) && columns[i] != "" {
+				fields[columns[i]] = value
+				if strings.TrimSpace(value) != "" {
+					empty = false
+				}
+			}
+		}
+		if empty {
+			continue
+		}
+
+		rows = append(rows, importRow{line: line, fields: fields})
+		if len(rows) > MaxImportRows {
+			return nil, fmt.Errorf("o CSV excede o limite de %d linhas", MaxImportRows)
+		}
+	}
+
+	return rows, nil
+}
+
+// parseImportImageUrls normaliza a lista de imagens separadas por "|" e retorna a primeira URL inválida, se houver
+func parseImportImageUrls(value string) (string, string) {
+	var urls []string
+	for _, url := range strings.Split(value, "|") {
+		url = strings.TrimSpace(url)
+		if url == "" {
+			continue
+		}
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return "", url
+		}
+		urls = append(urls, url)
+	}
+	return repository.JoinImageUrls(urls), ""
+}
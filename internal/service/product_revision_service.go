@@ -0,0 +1,105 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+)
+
+// DefaultRevisionRetention é o número de revisões mantidas por produto quando não configurado
+const DefaultRevisionRetention = 20
+
+/**
+ * ProductRevisionView is a revision as returned by the API, including the
+ * fields that the save following this revision changed.
+ */
+type ProductRevisionView struct {
+	Revision  int                   `json:"revision"`
+	CreatedAt time.Time             `json:"createdAt"`
+	Snapshot  model.ProductSnapshot `json:"snapshot"`
+	Changes   []model.FieldChange   `json:"changes"`
+}
+
+/**
+ * revisionRetention reads PRODUCT_REVISION_RETENTION, the number of revisions
+ * kept per product. Invalid or missing values fall back to the default.
+ */
+func revisionRetention() int {
+	if n, err := strconv.Atoi(os.Getenv("PRODUCT_REVISION_RETENTION")); err == nil && n > 0 {
+		return n
+	}
+	return DefaultRevisionRetention
+}
+
+// GetProductRevisions lista as revisões de um produto com o diff de cada uma
+func GetProductRevisions(productID uint) ([]ProductRevisionView, error) {
+	product, err := repository.GetProductWithTags(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("produto não encontrado")
+	}
+
+	revisions, err := repository.GetProductRevisions(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// As revisões vêm da mais recente para a mais antiga; cada uma é comparada
+	// com o estado seguinte, que para a mais recente é o produto atual.
+	next := model.SnapshotOf(product)
+	views := make([]ProductRevisionView, 0, len(revisions))
+	for _, rev := range revisions {
+		views = append(views, ProductRevisionView{
+			Revision:  rev.Revision,
+			CreatedAt: rev.CreatedAt,
+			Snapshot:  rev.Snapshot,
+			Changes:   rev.Snapshot.Diff(next),
+		})
+		next = rev.Snapshot
+	}
+
+	return views, nil
+}
+
+// RestoreProductRevision restaura o conteúdo de uma revisão; o estado atual vira uma nova revisão
+func RestoreProductRevision(productID uint, revisionNumber int) (*model.Product, error) {
+	revision, err := repository.GetProductRevision(productID, revisionNumber)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil {
+		return nil, errors.New("revisão não encontrada")
+	}
+
+	// Campos que a revisão não registrou (nil ou vazios) mantêm os valores atuais
+	snapshot := revision.Snapshot
+	restored := model.Product{
+		Name:         snapshot.Name,
+		Description:  snapshot.Description,
+		ImageUrls:    snapshot.ImageUrls,
+		PriceRange:   snapshot.PriceRange,
+		CategoryID:   snapshot.CategoryID,
+		Attributes:   snapshot.Attributes,
+		Translations: snapshot.Translations,
+		Availability: snapshot.Availability,
+		LeadTimeDays: snapshot.LeadTimeDays,
+	}
+	if snapshot.Tags != nil {
+		restored.Tags = make([]model.Tag, 0, len(snapshot.Tags))
+		for _, name := range snapshot.Tags {
+			restored.Tags = append(restored.Tags, model.Tag{Name: name})
+		}
+	}
+
+	if err := UpdateProduct(productID, &restored); err != nil {
+		return nil, err
+	}
+
+	return repository.GetProductByID(productID)
+}
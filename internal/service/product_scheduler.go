@@ -0,0 +1,45 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+)
+
+/**
+ * StartProductScheduler runs the publication schedule in the background,
+ * publishing and archiving products whose PublishAt/UnpublishAt has passed.
+ *
+ * @param interval - How often the schedule is checked
+ */
+func StartProductScheduler(interval time.Duration) {
+	go func() {
+		RunProductSchedule()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			RunProductSchedule()
+		}
+	}()
+}
+
+/**
+ * RunProductSchedule applies the due state changes once and invalidates
+ * the product cache when anything changed.
+ */
+func RunProductSchedule() {
+	published, archived, err := repository.ApplyProductSchedule(time.Now().UTC())
+	if err != nil {
+		log.Printf("Erro ao aplicar agendamento de produtos: %v", err)
+		return
+	}
+
+	if published > 0 || archived > 0 {
+		cacheService := &CacheService{}
+		cacheService.InvalidateProductCache()
+		log.Printf("Agendamento de produtos: %d publicados, %d arquivados", published, archived)
+	}
+}
@@ -3,20 +3,79 @@ package service
 import (
 	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+	"gorm.io/gorm"
 )
 
-// CreateProduct cria um novo produto
+// ErrInvalidProduct indica dados de produto rejeitados pela validação
+var ErrInvalidProduct = errors.New("produto inválido")
+
+// CreateProduct cria um novo produto; as tags informadas (só os nomes são usados) são gravadas junto, na mesma transação.
+// Falhas de validação envolvem ErrInvalidProduct.
 func CreateProduct(product *model.Product) error {
-	// Você pode adicionar validações, como verificar se a categoria existe.
 	if product.CategoryID == 0 {
-		return errors.New("categoria inválida")
+		return fmt.Errorf("%w: categoria inválida", ErrInvalidProduct)
+	}
+	// A chave estrangeira não enxerga o soft delete, então categorias na lixeira são barradas aqui
+	category, err := repository.GetCategoryByID(product.CategoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return fmt.Errorf("%w: categoria não encontrada", ErrInvalidProduct)
+	}
+
+	// Produtos novos nascem como rascunho, a menos que outro status seja informado
+	if product.Status == "" {
+		product.Status = model.ProductStatusDraft
+	}
+	if err := validateProductSchedule(product.Status, product.PublishAt, product.UnpublishAt); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+
+	if product.Availability == "" {
+		product.Availability = model.AvailabilityMadeToOrder
+	}
+	if err := validateProductAvailability(product.Availability, product.StockQuantity, product.LeadTimeDays); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+
+	attributes, err := validateProductAttributes(product.CategoryID, product.Attributes, true)
+	if err != nil {
+		return err
+	}
+	product.Attributes = attributes
+
+	if product.Translations, err = product.Translations.Normalize(model.ProductTranslatableFields); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+
+	// Tags são resolvidas antes de criar, para que um erro nelas não deixe o produto criado sem elas
+	if len(product.Tags) > 0 {
+		if product.Tags, err = resolveTags(model.TagNames(product.Tags)); err != nil {
+			return err
+		}
+	}
+
+	// Gera o slug único a partir do nome
+	slug, err := generateUniqueSlug(model.SlugEntityProduct, product.Name, 0)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar slug: %w", err)
+	}
+	product.Slug = slug
+
+	// Novos produtos entram no final da ordem manual da categoria
+	if product.Position, err = repository.NextProductPosition(product.CategoryID); err != nil {
+		return fmt.Errorf("erro ao calcular posição: %w", err)
 	}
 
 	// Chama o repositório para criar o produto.
-	err := repository.CreateProduct(product)
+	err = repository.CreateProduct(product)
 	if err != nil {
 		return fmt.Errorf("erro ao criar produto: %w", err)
 	}
@@ -31,13 +90,15 @@ func CreateProduct(product *model.Product) error {
 // AddProductImage adiciona um caminho de imagem ao produto
 func AddProductImage(productID uint, imagePath string) error {
 	// Buscar o produto pelo ID (opcional, para verificar se existe)
-	product, err := repository.GetProductByID(productID)
+	product, err := repository.GetProductWithTags(productID)
 	if err != nil {
 		return err
 	}
 	if product == nil {
 		return errors.New("produto não encontrado")
 	}
+	// Conteúdo atual, guardado como revisão junto com a alteração
+	previous := model.SnapshotOf(product)
 
 	// Aqui supomos que o campo ImageUrls é uma string que armazena os caminhos separados por vírgula.
 	// Em uma implementação real, pode ser um array ou uma tabela associada.
@@ -48,14 +109,25 @@ func AddProductImage(productID uint, imagePath string) error {
 	}
 
 	// Atualiza o produto com o novo caminho de imagem.
-	return repository.UpdateProduct(product)
+	if err := repository.UpdateProductWithRevision(product, previous); err != nil {
+		return err
+	}
+	if err := pruneProductRevisions([]uint{product.ID}); err != nil {
+		return err
+	}
+
+	// Invalida cache relacionado a produtos
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	return nil
 }
 
-func GetPaginatedProducts(limit int, offset int) ([]model.Product, error) {
+func GetPaginatedProducts(limit int, offset int, locale string) ([]model.Product, error) {
 	cacheService := &CacheService{}
 
 	// Tenta buscar no cache primeiro
-	if products, found := cacheService.GetCachedProducts(limit, offset); found {
+	if products, found := cacheService.GetCachedProducts(limit, offset, locale); found {
 		return products, nil
 	}
 
@@ -65,14 +137,16 @@ func GetPaginatedProducts(limit int, offset int) ([]model.Product, error) {
 		return nil, err
 	}
 
+	localizeProducts(products, locale)
+
 	// Armazena no cache
-	cacheService.SetCachedProducts(products, limit, offset)
+	cacheService.SetCachedProducts(products, limit, offset, locale)
 
 	return products, nil
 }
 
 // GetPaginatedProductsWithMetadata retorna produtos paginados com metadados
-func GetPaginatedProductsWithMetadata(page, limit int) (*model.PaginatedResponse, error) {
+func GetPaginatedProductsWithMetadata(page, limit int, filter model.ProductFilter) (*model.PaginatedResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -86,10 +160,11 @@ func GetPaginatedProductsWithMetadata(page, limit int) (*model.PaginatedResponse
 	offset := (page - 1) * limit
 
 	// Busca produtos e contagem total
-	products, total, err := repository.GetPaginatedProductsWithCount(limit, offset)
+	products, total, err := repository.GetPaginatedProductsWithCount(limit, offset, filter)
 	if err != nil {
 		return nil, err
 	}
+	localizeProducts(products, filter.Locale)
 
 	// Calcula metadados de paginação
 	metadata := model.CalculatePagination(page, limit, total)
@@ -100,11 +175,11 @@ func GetPaginatedProductsWithMetadata(page, limit int) (*model.PaginatedResponse
 	}, nil
 }
 
-func SearchProducts(searchTerm string, limit, offset int) ([]model.Product, error) {
+func SearchProducts(searchTerm string, limit, offset int, locale string) ([]model.Product, error) {
 	cacheService := &CacheService{}
 
 	// Tenta buscar no cache primeiro
-	if products, found := cacheService.GetCachedSearchProducts(searchTerm, limit, offset); found {
+	if products, found := cacheService.GetCachedSearchProducts(searchTerm, limit, offset, locale); found {
 		return products, nil
 	}
 
@@ -114,14 +189,16 @@ func SearchProducts(searchTerm string, limit, offset int) ([]model.Product, erro
 		return nil, err
 	}
 
+	localizeProducts(products, locale)
+
 	// Armazena no cache
-	cacheService.SetCachedSearchProducts(products, searchTerm, limit, offset)
+	cacheService.SetCachedSearchProducts(products, searchTerm, limit, offset, locale)
 
 	return products, nil
 }
 
 // SearchProductsWithMetadata retorna produtos pesquisados com metadados de paginação
-func SearchProductsWithMetadata(searchTerm string, page, limit int) (*model.PaginatedResponse, error) {
+func SearchProductsWithMetadata(searchTerm string, page, limit int, filter model.ProductFilter) (*model.PaginatedResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -135,10 +212,11 @@ func SearchProductsWithMetadata(searchTerm string, page, limit int) (*model.Pagi
 	offset := (page - 1) * limit
 
 	// Busca produtos e contagem total
-	products, total, err := repository.SearchProductsByNameWithCount(searchTerm, limit, offset)
+	products, total, err := repository.SearchProductsByNameWithCount(searchTerm, limit, offset, filter)
 	if err != nil {
 		return nil, err
 	}
+	localizeProducts(products, filter.Locale)
 
 	// Calcula metadados de paginação
 	metadata := model.CalculatePagination(page, limit, total)
@@ -151,7 +229,7 @@ func SearchProductsWithMetadata(searchTerm string, page, limit int) (*model.Pagi
 
 // DeleteProductImage remove uma imagem do produto dado seu índice (posição na lista)
 func DeleteProductImage(productID uint, index int) error {
-	product, err := repository.GetProductByID(productID)
+	product, err := repository.GetProductWithTags(productID)
 	if err != nil {
 		return err
 	}
@@ -165,12 +243,42 @@ func DeleteProductImage(productID uint, index int) error {
 		return errors.New("índice de imagem inválido")
 	}
 
+	// Guarda o conteúdo atual como revisão, na mesma transação que remove a imagem
+	previous := model.SnapshotOf(product)
+
 	// Remove a imagem do slice
+	removed := imagePaths[index]
 	imagePaths = append(imagePaths[:index], imagePaths[index+1:]...)
 	// Atualiza o campo ImageUrls
 	product.ImageUrls = repository.JoinImageUrls(imagePaths)
 
-	return repository.UpdateProduct(product)
+	if err := repository.UpdateProductWithRevision(product, previous); err != nil {
+		return err
+	}
+	if err := pruneProductRevisions([]uint{product.ID}); err != nil {
+		return err
+	}
+
+	// Invalida cache relacionado a produtos
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	// O arquivo pode ser compartilhado com cópias do produto; só é apagado quando ninguém mais o usa
+	if _, err := deleteImageFileIfUnused(removed); err != nil {
+		log.Printf("Erro ao remover arquivo da imagem %s: %v", removed, err)
+	}
+
+	return nil
+}
+
+// localizeProducts aplica o idioma pedido aos textos de uma lista de produtos e preenche os breadcrumbs
+func localizeProducts(products []model.Product, locale string) {
+	refs := make([]*model.Product, len(products))
+	for i := range products {
+		products[i].Localize(locale)
+		refs[i] = &products[i]
+	}
+	setBreadcrumbs(refs, locale)
 }
 
 // GetProducts retorna todos os produtos
@@ -182,31 +290,100 @@ func GetProducts() ([]model.Product, error) {
 	return products, nil
 }
 
-// GetProductsByCategory retorna os produtos filtrados por categoria
-func GetProductsByCategory(categoryID uint) ([]model.Product, error) {
+// GetProductsByCategory retorna os produtos filtrados por categoria, opcionalmente com os das subcategorias
+func GetProductsByCategory(categoryID uint, filter model.ProductFilter) ([]model.Product, error) {
 	cacheService := &CacheService{}
 
 	// Tenta buscar no cache primeiro
-	if products, found := cacheService.GetCachedProductsByCategory(categoryID); found {
+	if products, found := cacheService.GetCachedProductsByCategory(categoryID, filter); found {
 		return products, nil
 	}
 
 	// Se não encontrou no cache, busca no banco
-	products, err := repository.GetProductsByCategory(categoryID)
+	categoryIDs := []uint{categoryID}
+	if filter.IncludeDescendants {
+		ids, err := getCategoryDescendantIDs(categoryID)
+		if err != nil {
+			return nil, err
+		}
+		categoryIDs = ids
+	}
+	products, err := repository.GetProductsByCategory(categoryIDs, filter)
 	if err != nil {
 		return nil, err
 	}
+	localizeProducts(products, filter.Locale)
 
 	// Armazena no cache
-	cacheService.SetCachedProductsByCategory(products, categoryID)
+	cacheService.SetCachedProductsByCategory(products, categoryID, filter)
 
 	return products, nil
 }
 
-// GetProductImages retorna as imagens de um produto
+// GetFeaturedProducts retorna os produtos em destaque, com os textos no idioma pedido
+func GetFeaturedProducts(limit int, locale string) ([]model.Product, error) {
+	if limit < 1 {
+		limit = 12
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	cacheService := &CacheService{}
+	if products, found := cacheService.GetCachedFeaturedProducts(limit, locale); found {
+		return products, nil
+	}
+
+	products, err := repository.GetFeaturedProducts(limit)
+	if err != nil {
+		return nil, err
+	}
+	localizeProducts(products, locale)
+
+	cacheService.SetCachedFeaturedProducts(products, limit, locale)
+
+	return products, nil
+}
+
+// SetProductFeatured marca ou desmarca um produto como destaque
+func SetProductFeatured(productID uint, featured bool) error {
+	if err := repository.SetProductFeatured(productID, featured); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("produto não encontrado")
+		}
+		return err
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	return nil
+}
+
+// ReorderCategoryProducts define a ordem manual dos produtos de uma categoria
+func ReorderCategoryProducts(categoryID uint, productIDs []uint) error {
+	category, err := repository.GetCategoryByID(categoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return errors.New("categoria não encontrada")
+	}
+
+	if err := repository.ReorderCategoryProducts(categoryID, productIDs); err != nil {
+		return err
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	return nil
+}
+
+// GetProductImages retorna as imagens de um produto visível na vitrine
 // Se o campo ImageUrls for uma string separada por vírgula, podemos utilizar essa lógica para transformá-la em slice.
 func GetProductImages(productID uint) ([]string, error) {
-	product, err := repository.GetProductByID(productID)
+	product, err := repository.GetVisibleProductByID(productID)
 	if err != nil {
 		return nil, err
 	}
@@ -242,29 +419,322 @@ func DeleteProduct(productID uint) error {
 	return nil
 }
 
-// UpdateProduct no serviço
+/**
+ * UpdateProduct replaces the editable content of a product. When
+ * updatedProduct.Version is set it must match the stored version, otherwise
+ * repository.ErrVersionConflict is returned and nothing is saved. Tags, when
+ * not nil, replace the current ones in the same transaction; only their
+ * names are used. Availability, when set, replaces the availability and the
+ * lead time. Validation failures wrap ErrInvalidProduct.
+ *
+ * @param productID - The product to update
+ * @param updatedProduct - The new content and, optionally, the version the admin edited
+ */
 func UpdateProduct(productID uint, updatedProduct *model.Product) error {
-	// Primeiro, tenta buscar o produto pelo ID
-	product, err := repository.GetProductByID(productID)
+	// Primeiro, tenta buscar o produto pelo ID, com as tags para a revisão
+	product, err := repository.GetProductWithTags(productID)
 	if err != nil {
 		return err // Retorna erro se não encontrar o produto
 	}
+	if product == nil {
+		return errors.New("produto não encontrado")
+	}
+	if updatedProduct.Version != 0 && updatedProduct.Version != product.Version {
+		return repository.ErrVersionConflict
+	}
+	if updatedProduct.CategoryID == 0 {
+		return fmt.Errorf("%w: categoria inválida", ErrInvalidProduct)
+	}
+	if strings.TrimSpace(updatedProduct.Name) == "" {
+		return fmt.Errorf("%w: o nome é obrigatório", ErrInvalidProduct)
+	}
+	// Conteúdo atual, guardado como revisão na mesma transação que o sobrescreve
+	previous := model.SnapshotOf(product)
+
+	// Tags são resolvidas antes de gravar, para que um erro nelas não deixe o produto salvo pela metade
+	if updatedProduct.Tags != nil {
+		tags, err := resolveTags(model.TagNames(updatedProduct.Tags))
+		if err != nil {
+			return err
+		}
+		product.Tags = tags
+	}
+
+	// Se o nome mudou, gera um novo slug e guarda o antigo no histórico
+	if updatedProduct.Name != product.Name {
+		slug, err := renameSlug(model.SlugEntityProduct, product.ID, product.Slug, updatedProduct.Name)
+		if err != nil {
+			return fmt.Errorf("erro ao gerar slug: %w", err)
+		}
+		product.Slug = slug
+	}
+	updatedProduct.Slug = product.Slug
+
+	// Ao trocar de categoria, o produto vai para o final da ordem manual da nova categoria
+	if updatedProduct.CategoryID != product.CategoryID {
+		category, err := repository.GetCategoryByID(updatedProduct.CategoryID)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return fmt.Errorf("%w: categoria não encontrada", ErrInvalidProduct)
+		}
+		position, err := repository.NextProductPosition(updatedProduct.CategoryID)
+		if err != nil {
+			return fmt.Errorf("erro ao calcular posição: %w", err)
+		}
+		product.Position = position
+	}
+
+	// Atributos ausentes mantêm os valores atuais, descartando os que não existem no esquema da nova categoria
+	if updatedProduct.Attributes != nil {
+		attributes, err := validateProductAttributes(updatedProduct.CategoryID, updatedProduct.Attributes, true)
+		if err != nil {
+			return err
+		}
+		updatedProduct.Attributes = attributes
+	} else if updatedProduct.CategoryID != product.CategoryID {
+		category, err := repository.GetCategoryByID(updatedProduct.CategoryID)
+		if err != nil {
+			return err
+		}
+		if category != nil {
+			updatedProduct.Attributes = category.AttributeSchema.Prune(product.Attributes)
+		}
+	} else {
+		updatedProduct.Attributes = product.Attributes
+	}
+
+	// Traduções ausentes mantêm as atuais
+	if updatedProduct.Translations != nil {
+		translations, err := updatedProduct.Translations.Normalize(model.ProductTranslatableFields)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+		}
+		product.Translations = translations
+	}
+
+	// Disponibilidade ausente mantém a atual; o estoque não muda por aqui
+	if updatedProduct.Availability != "" {
+		if err := validateProductAvailability(updatedProduct.Availability, product.StockQuantity, updatedProduct.LeadTimeDays); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+		}
+		product.Availability = updatedProduct.Availability
+		product.LeadTimeDays = updatedProduct.LeadTimeDays
+	}
 
 	// Atualiza os campos do produto
 	product.Name = updatedProduct.Name
 	product.Description = updatedProduct.Description
 	product.ImageUrls = updatedProduct.ImageUrls
 	product.PriceRange = updatedProduct.PriceRange
+	if updatedProduct.CategoryID != product.CategoryID {
+		product.Category = model.Category{}
+	}
 	product.CategoryID = updatedProduct.CategoryID
+	product.Attributes = updatedProduct.Attributes
 
 	// Atualiza o produto no banco de dados
-	if err := repository.UpdateProduct(product); err != nil {
+	if err := repository.UpdateProductWithRevision(product, previous); err != nil {
 		return err // Retorna erro se falhar ao atualizar no banco
 	}
+	if err := pruneProductRevisions([]uint{product.ID}); err != nil {
+		return err
+	}
+	*updatedProduct = *product
+
+	// Invalida cache relacionado a produtos
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	return nil
+}
+
+// validateProductSchedule valida o status e a janela de publicação de um produto
+func validateProductSchedule(status model.ProductStatus, publishAt, unpublishAt *time.Time) error {
+	if !status.IsValid() {
+		return errors.New("status inválido: use draft, published ou archived")
+	}
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return errors.New("unpublishAt deve ser maior que publishAt")
+	}
+	return nil
+}
+
+// UpdateProductStatus altera o status e o agendamento de publicação de um produto
+func UpdateProductStatus(productID uint, status model.ProductStatus, publishAt, unpublishAt *time.Time) (*model.Product, error) {
+	product, err := repository.GetProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("produto não encontrado")
+	}
+
+	if err := validateProductSchedule(status, publishAt, unpublishAt); err != nil {
+		return nil, err
+	}
+
+	product.Status = status
+	product.PublishAt = publishAt
+	product.UnpublishAt = unpublishAt
+
+	if err := repository.UpdateProduct(product); err != nil {
+		return nil, err
+	}
 
 	// Invalida cache relacionado a produtos
 	cacheService := &CacheService{}
 	cacheService.InvalidateProductCache()
 
+	return product, nil
+}
+
+// validateProductAttributes valida os atributos do produto contra o esquema da categoria; valores rejeitados envolvem ErrInvalidProduct
+func validateProductAttributes(categoryID uint, values model.ProductAttributes, requireAll bool) (model.ProductAttributes, error) {
+	category, err := repository.GetCategoryByID(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	var schema model.AttributeSchema
+	if category != nil {
+		schema = category.AttributeSchema
+	}
+	attributes, err := schema.ValidateValues(values, requireAll)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+	return attributes, nil
+}
+
+// validateProductAvailability valida a disponibilidade, o estoque e o prazo de produção
+func validateProductAvailability(availability model.ProductAvailability, stockQuantity, leadTimeDays int) error {
+	if !availability.IsValid() {
+		return errors.New("disponibilidade inválida: use in_stock, made_to_order ou unavailable")
+	}
+	if stockQuantity < 0 {
+		return errors.New("estoque não pode ser negativo")
+	}
+	if leadTimeDays < 0 {
+		return errors.New("prazo de produção não pode ser negativo")
+	}
 	return nil
 }
+
+// UpdateProductAvailability altera a disponibilidade, o estoque e o prazo de produção de um produto
+func UpdateProductAvailability(productID uint, availability model.ProductAvailability, stockQuantity, leadTimeDays int) (*model.Product, error) {
+	if err := validateProductAvailability(availability, stockQuantity, leadTimeDays); err != nil {
+		return nil, err
+	}
+
+	product, err := repository.GetProductWithTags(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("produto não encontrado")
+	}
+
+	if err := repository.UpdateProductAvailability(productID, availability, stockQuantity, leadTimeDays, model.SnapshotOf(product)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("produto não encontrado")
+		}
+		return nil, err
+	}
+	if err := pruneProductRevisions([]uint{productID}); err != nil {
+		return nil, err
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	return repository.GetProductByID(productID)
+}
+
+/**
+ * AdjustProductStock adds or removes units from a product's stock, e.g. after
+ * a sale. The change is atomic and fails instead of going below zero.
+ *
+ * @param productID - The product to adjust
+ * @param delta - Units to add or, when negative, to remove
+ * @returns - The stock after the change
+ */
+func AdjustProductStock(productID uint, delta int) (int, error) {
+	if delta == 0 {
+		return 0, errors.New("quantidade deve ser diferente de zero")
+	}
+
+	stock, err := repository.AdjustProductStock(productID, delta)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("produto não encontrado")
+		}
+		return 0, err
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	return stock, nil
+}
+
+// GetAdminProductsWithMetadata lista todos os produtos (qualquer status) para o painel admin
+func GetAdminProductsWithMetadata(status, searchTerm string, page, limit int) (*model.PaginatedResponse, error) {
+	if status != "" && status != "scheduled" && !model.ProductStatus(status).IsValid() {
+		return nil, errors.New("status inválido")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	offset := (page - 1) * limit
+
+	products, total, err := repository.GetAdminProductsWithCount(status, searchTerm, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Data:     products,
+		Metadata: model.CalculatePagination(page, limit, total),
+	}, nil
+}
+
+// DuplicateProduct cria um novo produto em rascunho a partir de um existente
+func DuplicateProduct(productID uint) (*model.Product, error) {
+	source, err := repository.GetProductWithTags(productID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, errors.New("produto não encontrado")
+	}
+
+	// As imagens apontam para os mesmos arquivos do original; o estoque é da peça física, então a cópia começa zerada
+	clone := model.Product{
+		Name:         source.Name + " (cópia)",
+		Description:  source.Description,
+		ImageUrls:    source.ImageUrls,
+		PriceRange:   source.PriceRange,
+		CategoryID:   source.CategoryID,
+		Status:       model.ProductStatusDraft,
+		Availability: source.Availability,
+		LeadTimeDays: source.LeadTimeDays,
+		Attributes:   source.Attributes,
+		Translations: source.Translations,
+		Tags:         source.Tags,
+	}
+
+	// As tags são gravadas junto com a cópia, na mesma transação
+	if err := CreateProduct(&clone); err != nil {
+		return nil, err
+	}
+
+	return &clone, nil
+}
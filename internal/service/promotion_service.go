@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"time"
 
@@ -39,6 +40,18 @@ func ValidatePromotion(p *model.Promotion) error {
 		}
 	}
 
+	// Alvos e exclusões: categorias, produtos e tags precisam existir
+	if err := validatePromotionTargets(p); err != nil {
+		return err
+	}
+
+	// Traduções de messageTemplate e bannerTitle
+	translations, err := p.Translations.Normalize(model.PromotionTranslatableFields)
+	if err != nil {
+		return err
+	}
+	p.Translations = translations
+
 	// Tempo: start <= end
 	if p.StartAt != nil && p.EndAt != nil {
 		if p.EndAt.Before(*p.StartAt) {
@@ -118,19 +131,50 @@ func ValidatePromotion(p *model.Promotion) error {
 	return nil
 }
 
-// SavePromotion salva ou atualiza a promoção atual
-func SavePromotion(p *model.Promotion) (*model.Promotion, error) {
+// ErrPromotionNotFound indica que a promoção pedida não existe
+var ErrPromotionNotFound = errors.New("promoção não encontrada")
+
+/**
+ * SavePromotion keeps the legacy single-promotion endpoint working. The
+ * payload replaces the promotion currently shown on the storefront or, when
+ * none is active, the last one edited; with no promotions at all it creates
+ * one. Name and priority are managed only through /admin/promotions, so the
+ * stored values are kept. Targets, excludes and translations are newer than
+ * this endpoint's payload: they keep the stored values unless sent.
+ *
+ * @param p - The full promotion payload
+ * @param sent - The top-level JSON keys present in the request body
+ */
+func SavePromotion(p *model.Promotion, sent map[string]bool) (*model.Promotion, error) {
 	if err := ValidatePromotion(p); err != nil {
 		return nil, err
 	}
 
-	existing, err := repository.GetLatestPromotion()
+	existing, err := repository.GetCurrentPromotion(time.Now().UTC())
 	if err != nil {
 		return nil, err
 	}
+	if existing == nil {
+		if existing, err = repository.GetLatestPromotion(); err != nil {
+			return nil, err
+		}
+	}
 
 	if existing != nil {
 		p.ID = existing.ID
+		p.CreatedAt = existing.CreatedAt
+		p.Name = existing.Name
+		p.Priority = existing.Priority
+		// Clientes antigos não conhecem esses campos; sem eles a promoção viraria da loja toda e perderia as traduções
+		if !sent["targets"] {
+			p.Targets = existing.Targets
+		}
+		if !sent["excludes"] {
+			p.Excludes = existing.Excludes
+		}
+		if !sent["translations"] {
+			p.Translations = existing.Translations
+		}
 		if err := repository.SavePromotion(p); err != nil {
 			return nil, err
 		}
@@ -143,26 +187,105 @@ func SavePromotion(p *model.Promotion) (*model.Promotion, error) {
 	return p, nil
 }
 
-// GetActivePromotion retorna a promoção ativa (enabled e dentro da janela), senão nil
-func GetActivePromotion() (*model.Promotion, error) {
-	p, err := repository.GetLatestPromotion()
+// GetActivePromotion retorna a promoção que vence agora (ligada, dentro da janela e de maior prioridade) no idioma pedido, senão nil
+func GetActivePromotion(locale string) (*model.Promotion, error) {
+	p, err := repository.GetCurrentPromotion(time.Now().UTC())
 	if err != nil {
 		return nil, err
 	}
 	if p == nil {
 		return nil, nil
 	}
-	if !p.Enabled {
-		return nil, nil
+
+	p.Localize(locale)
+	return p, nil
+}
+
+// GetPromotions lista todas as promoções da que vence para a que perde, com a situação de cada uma agora (admin)
+func GetPromotions() ([]model.Promotion, error) {
+	promotions, err := repository.GetPromotionsByPrecedence()
+	if err != nil {
+		return nil, err
 	}
 
+	// A lista já vem na ordem de precedência: a primeira dentro da janela é a vencedora
 	now := time.Now().UTC()
-	if p.StartAt != nil && now.Before(*p.StartAt) {
-		return nil, nil
-	}
-	if p.EndAt != nil && now.After(*p.EndAt) {
-		return nil, nil
+	found := false
+	for i := range promotions {
+		current := !found && promotions[i].InWindow(now)
+		found = found || current
+		promotions[i].Status = promotions[i].StatusAt(now, current)
 	}
+	return promotions, nil
+}
 
+// GetPromotion retorna uma promoção pelo ID, com a situação dela agora (admin)
+func GetPromotion(promotionID uint) (*model.Promotion, error) {
+	p, err := repository.GetPromotionByID(promotionID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrPromotionNotFound
+	}
+	if err := setPromotionStatus(p); err != nil {
+		return nil, err
+	}
 	return p, nil
 }
+
+// CreatePromotion cadastra uma nova promoção, com janela e prioridade próprias (admin)
+func CreatePromotion(p *model.Promotion) error {
+	p.ID = 0
+	if err := ValidatePromotion(p); err != nil {
+		return err
+	}
+	if err := repository.CreatePromotion(p); err != nil {
+		return fmt.Errorf("erro ao criar promoção: %w", err)
+	}
+	return setPromotionStatus(p)
+}
+
+// UpdatePromotion substitui todos os dados de uma promoção existente (admin)
+func UpdatePromotion(promotionID uint, p *model.Promotion) error {
+	existing, err := repository.GetPromotionByID(promotionID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrPromotionNotFound
+	}
+	if err := ValidatePromotion(p); err != nil {
+		return err
+	}
+
+	p.ID = existing.ID
+	p.CreatedAt = existing.CreatedAt
+	if err := repository.SavePromotion(p); err != nil {
+		return fmt.Errorf("erro ao atualizar promoção: %w", err)
+	}
+	return setPromotionStatus(p)
+}
+
+// DeletePromotion deleta uma promoção (admin)
+func DeletePromotion(promotionID uint) error {
+	deleted, err := repository.DeletePromotion(promotionID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrPromotionNotFound
+	}
+	return nil
+}
+
+// setPromotionStatus preenche a situação da promoção, comparando com a vencedora atual
+func setPromotionStatus(p *model.Promotion) error {
+	now := time.Now().UTC()
+	current, err := repository.GetCurrentPromotion(now)
+	if err != nil {
+		return err
+	}
+	p.Status = p.StatusAt(now, current != nil && current.ID == p.ID)
+	return nil
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
+)
+
+/**
+ * validatePromotionTargets normalizes the targets and excludes of a
+ * promotion (repeated IDs dropped, tags turned into slugs) and checks that
+ * every category, product and tag exists and that nothing is both targeted
+ * and excluded.
+ */
+func validatePromotionTargets(p *model.Promotion) error {
+	for _, t := range []*model.PromotionTargets{&p.Targets, &p.Excludes} {
+		t.CategoryIDs = uniqueIDs(t.CategoryIDs)
+		t.ProductIDs = uniqueIDs(t.ProductIDs)
+		t.Tags = tagSlugs(t.Tags)
+	}
+	if p.Targets.IsEmpty() && p.Excludes.IsEmpty() {
+		return nil
+	}
+
+	categoryIDs := append(append([]uint{}, p.Targets.CategoryIDs...), p.Excludes.CategoryIDs...)
+	if len(categoryIDs) > 0 {
+		categories, err := repository.GetCategories()
+		if err != nil {
+			return err
+		}
+		idx := newCategoryIndex(categories)
+		for _, id := range categoryIDs {
+			if idx.byID[id] == nil {
+				return fmt.Errorf("categoria %d não encontrada", id)
+			}
+		}
+	}
+
+	productIDs := append(append([]uint{}, p.Targets.ProductIDs...), p.Excludes.ProductIDs...)
+	if len(productIDs) > 0 {
+		products, err := repository.GetProductsByIDs(productIDs)
+		if err != nil {
+			return err
+		}
+		found := make(map[uint]bool, len(products))
+		for _, product := range products {
+			found[product.ID] = true
+		}
+		for _, id := range productIDs {
+			if !found[id] {
+				return fmt.Errorf("produto %d não encontrado", id)
+			}
+		}
+	}
+
+	slugs := append(append([]string{}, p.Targets.Tags...), p.Excludes.Tags...)
+	if len(slugs) > 0 {
+		tags, err := repository.GetTagsBySlugs(slugs)
+		if err != nil {
+			return err
+		}
+		found := make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			found[tag.Slug] = true
+		}
+		for _, slug := range slugs {
+			if !found[slug] {
+				return fmt.Errorf("tag %q não encontrada", slug)
+			}
+		}
+	}
+
+	for _, id := range p.Targets.CategoryIDs {
+		if containsID(p.Excludes.CategoryIDs, id) {
+			return fmt.Errorf("categoria %d está nos alvos e nas exclusões", id)
+		}
+	}
+	for _, id := range p.Targets.ProductIDs {
+		if containsID(p.Excludes.ProductIDs, id) {
+			return fmt.Errorf("produto %d está nos alvos e nas exclusões", id)
+		}
+	}
+	for _, slug := range p.Targets.Tags {
+		for _, excluded := range p.Excludes.Tags {
+			if slug == excluded {
+				return fmt.Errorf("tag %q está nos alvos e nas exclusões", slug)
+			}
+		}
+	}
+	return nil
+}
+
+// containsID indica se o ID está na lista
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// promotionMatcher decide se uma promoção vale para um produto, com as categorias alvo já expandidas para as subcategorias
+type promotionMatcher struct {
+	promotion          *model.Promotion
+	categories         map[uint]bool
+	products           map[uint]bool
+	tags               map[string]bool
+	excludedCategories map[uint]bool
+	excludedProducts   map[uint]bool
+	excludedTags       map[string]bool
+}
+
+func newPromotionMatcher(p *model.Promotion, idx *categoryIndex) *promotionMatcher {
+	expand := func(ids []uint) map[uint]bool {
+		set := map[uint]bool{}
+		for _, id := range ids {
+			for _, descendant := range idx.descendants(id) {
+				set[descendant] = true
+			}
+		}
+		return set
+	}
+	idSet := func(ids []uint) map[uint]bool {
+		set := make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			set[id] = true
+		}
+		return set
+	}
+	slugSet := func(slugs []string) map[string]bool {
+		set := make(map[string]bool, len(slugs))
+		for _, slug := range slugs {
+			set[slug] = true
+		}
+		return set
+	}
+
+	return &promotionMatcher{
+		promotion:          p,
+		categories:         expand(p.Targets.CategoryIDs),
+		products:           idSet(p.Targets.ProductIDs),
+		tags:               slugSet(p.Targets.Tags),
+		excludedCategories: expand(p.Excludes.CategoryIDs),
+		excludedProducts:   idSet(p.Excludes.ProductIDs),
+		excludedTags:       slugSet(p.Excludes.Tags),
+	}
+}
+
+// matches indica se a promoção vale para o produto: exclusões sempre vencem e sem alvos vale para a loja toda
+func (m *promotionMatcher) matches(product *model.Product, tagSlugs []string) bool {
+	if m.excludedProducts[product.ID] || m.excludedCategories[product.CategoryID] {
+		return false
+	}
+	for _, slug := range tagSlugs {
+		if m.excludedTags[slug] {
+			return false
+		}
+	}
+
+	if m.promotion.Targets.IsEmpty() {
+		return true
+	}
+	if m.products[product.ID] || m.categories[product.CategoryID] {
+		return true
+	}
+	for _, slug := range tagSlugs {
+		if m.tags[slug] {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * ApplyPromotionDiscounts fills the discount of each product with the first
+ * running promotion, in precedence order, that has a GlobalPercentage and
+ * targets it. Progressive rules depend on the cart and are left to the
+ * frontend. Failures are logged and the products are served without discount.
+ *
+ * @param products - The products of a public response
+ */
+func ApplyPromotionDiscounts(products []model.Product) {
+	if len(products) == 0 {
+		return
+	}
+
+	running, err := repository.GetRunningPromotions(time.Now().UTC())
+	if err != nil {
+		log.Printf("Erro ao carregar promoções: %v", err)
+		return
+	}
+	var promotions []model.Promotion
+	needsTags := false
+	for _, p := range running {
+		if p.GlobalPercentage != nil && *p.GlobalPercentage > 0 {
+			promotions = append(promotions, p)
+			needsTags = needsTags || len(p.Targets.Tags) > 0 || len(p.Excludes.Tags) > 0
+		}
+	}
+	if len(promotions) == 0 {
+		return
+	}
+
+	categories, err := repository.GetCategories()
+	if err != nil {
+		log.Printf("Erro ao carregar categorias das promoções: %v", err)
+		return
+	}
+	idx := newCategoryIndex(categories)
+	matchers := make([]*promotionMatcher, len(promotions))
+	for i := range promotions {
+		matchers[i] = newPromotionMatcher(&promotions[i], idx)
+	}
+
+	var tagSlugsByProduct map[uint][]string
+	if needsTags {
+		ids := make([]uint, len(products))
+		for i := range products {
+			ids[i] = products[i].ID
+		}
+		if tagSlugsByProduct, err = repository.GetProductTagSlugs(ids); err != nil {
+			log.Printf("Erro ao carregar tags das promoções: %v", err)
+			return
+		}
+	}
+
+	for i := range products {
+		for _, m := range matchers {
+			if m.matches(&products[i], tagSlugsByProduct[products[i].ID]) {
+				products[i].Discount = discountPrice(products[i].PriceRange, m.promotion)
+				break
+			}
+		}
+	}
+}
+
+// discountPrice calcula o preço com o desconto da promoção; nil se o preço não tiver valores
+func discountPrice(priceRange string, p *model.Promotion) *model.ProductDiscount {
+	min, max, ok := util.ParsePriceRange(priceRange)
+	if !ok {
+		return nil
+	}
+	percentage := *p.GlobalPercentage
+	text, _ := util.AdjustPriceText(priceRange, -float64(percentage))
+	factor := 1 - float64(percentage)/100
+
+	return &model.ProductDiscount{
+		PromotionID:   p.ID,
+		PromotionName: p.Name,
+		Percentage:    percentage,
+		Min:           math.Round(min*factor*100) / 100,
+		Max:           math.Round(max*factor*100) / 100,
+		Text:          text,
+		EndAt:         p.EndAt,
+	}
+}
+
+// ApplyPricing preenche os descontos das promoções e depois converte os preços para a moeda pedida (nil para a moeda base)
+func ApplyPricing(products []model.Product, rate *model.ExchangeRate) {
+	ApplyPromotionDiscounts(products)
+	ApplyExchangeRate(products, rate)
+}
+
+// ApplyProductPricing faz o mesmo que ApplyPricing para um único produto
+func ApplyProductPricing(product *model.Product, rate *model.ExchangeRate) {
+	products := []model.Product{*product}
+	ApplyPricing(products, rate)
+	*product = products[0]
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
+)
+
+// Pesos usados para ranquear produtos relacionados
+const (
+	relatedCategoryWeight = 3.0
+	relatedPriceWeight    = 2.0
+	relatedTagWeight      = 1.0
+	relatedCoViewWeight   = 2.0
+	relatedMaxSharedTags  = 3
+	relatedCandidateLimit = 200
+	DefaultRelatedLimit   = 8
+	MaxRelatedLimit       = 24
+)
+
+/**
+ * GetRelatedProducts ranks other visible products by how similar they are to
+ * the given one: same category, shared tags, a close price and how often
+ * visitors viewed both products in the same session. Results are cached and
+ * the cache is cleared whenever products change.
+ *
+ * @param productID - The product shown on the page
+ * @param limit - Maximum number of recommendations
+ * @param locale - Language of the returned texts
+ */
+func GetRelatedProducts(productID uint, limit int, locale string) ([]model.Product, error) {
+	if limit < 1 {
+		limit = DefaultRelatedLimit
+	}
+	if limit > MaxRelatedLimit {
+		limit = MaxRelatedLimit
+	}
+
+	cacheService := &CacheService{}
+	if products, found := cacheService.GetCachedRelatedProducts(productID, limit, locale); found {
+		return products, nil
+	}
+
+	product, err := repository.GetVisibleProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("produto não encontrado")
+	}
+
+	candidates, err := repository.GetRelatedCandidates(product, relatedCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	coViews, err := repository.GetCoViewCounts(product.ID)
+	if err != nil {
+		return nil, err
+	}
+	var maxCoViews int64
+	for _, count := range coViews {
+		if count > maxCoViews {
+			maxCoViews = count
+		}
+	}
+
+	type scored struct {
+		product model.Product
+		score   float64
+	}
+	ranked := make([]scored, 0, len(candidates))
+	for _, candidate := range candidates {
+		score := relatedScore(product, &candidate)
+		if maxCoViews > 0 {
+			// Co-visualizações normalizadas pelo par mais visto, entre 0 e 1
+			score += relatedCoViewWeight * float64(coViews[candidate.ID]) / float64(maxCoViews)
+		}
+		ranked = append(ranked, scored{product: candidate, score: score})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+
+	related := make([]model.Product, 0, limit)
+	for _, r := range ranked {
+		if len(related) == limit || r.score <= 0 {
+			break
+		}
+		related = append(related, r.product)
+	}
+
+	localizeProducts(related, locale)
+
+	cacheService.SetCachedRelatedProducts(related, productID, limit, locale)
+
+	return related, nil
+}
+
+// relatedScore calcula a afinidade entre o produto de origem e um candidato
+func relatedScore(source, candidate *model.Product) float64 {
+	score := 0.0
+
+	if source.CategoryID == candidate.CategoryID {
+		score += relatedCategoryWeight
+	}
+
+	score += relatedTagWeight * float64(sharedTags(source.Tags, candidate.Tags))
+
+	score += relatedPriceWeight * priceSimilarity(source.PriceRange, candidate.PriceRange)
+
+	return score
+}
+
+// sharedTags conta as tags em comum, limitado para que tags não pesem mais que a categoria
+func sharedTags(a, b []model.Tag) int {
+	ids := make(map[uint]bool, len(a))
+	for _, tag := range a {
+		ids[tag.ID] = true
+	}
+	shared := 0
+	for _, tag := range b {
+		if ids[tag.ID] {
+			shared++
+		}
+	}
+	if shared > relatedMaxSharedTags {
+		shared = relatedMaxSharedTags
+	}
+	return shared
+}
+
+// priceSimilarity retorna 1 para preços iguais e tende a 0 conforme a diferença aumenta
+func priceSimilarity(a, b string) float64 {
+	aMin, aMax, okA := util.ParsePriceRange(a)
+	bMin, bMax, okB := util.ParsePriceRange(b)
+	if !okA || !okB {
+		return 0
+	}
+
+	aMid := (aMin + aMax) / 2
+	bMid := (bMin + bMax) / 2
+	highest := math.Max(aMid, bMid)
+	if highest == 0 {
+		return 1
+	}
+	return 1 - math.Abs(aMid-bMid)/highest
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+)
+
+// Limites das avaliações enviadas pelos clientes
+const (
+	MaxReviewPhotos      = 3
+	MaxReviewTextLength  = 2000
+	MaxReviewReplyLength = 2000
+)
+
+/**
+ * CreateReview posts a review from a logged-in user. The review waits in the
+ * moderation queue and only counts towards the product rating once approved.
+ * Each user may have a single pending or approved review per product.
+ *
+ * @param productID - The reviewed product, which must be visible in the store
+ * @param userID - The author, taken from the access token
+ * @param rating - Stars from 1 to 5
+ * @param text - The review text
+ * @param photos - Optional photos, uploaded before the review is saved
+ */
+func CreateReview(productID, userID uint, rating int, text string, photos []*multipart.FileHeader) (*model.Review, error) {
+	if rating < 1 || rating > 5 {
+		return nil, errors.New("a nota deve ser de 1 a 5 estrelas")
+	}
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > MaxReviewTextLength {
+		return nil, fmt.Errorf("o texto deve ter no máximo %d caracteres", MaxReviewTextLength)
+	}
+	if len(photos) > MaxReviewPhotos {
+		return nil, fmt.Errorf("envie no máximo %d fotos", MaxReviewPhotos)
+	}
+
+	product, err := repository.GetVisibleProductByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("produto não encontrado")
+	}
+
+	user, err := repository.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+
+	exists, err := repository.HasActiveReview(productID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, repository.ErrReviewExists
+	}
+
+	photoUrls, err := uploadReviewPhotos(photos)
+	if err != nil {
+		return nil, err
+	}
+
+	review := model.Review{
+		ProductID:  productID,
+		UserID:     userID,
+		AuthorName: user.Name,
+		Rating:     rating,
+		Text:       text,
+		PhotoUrls:  repository.JoinImageUrls(photoUrls),
+		Status:     model.ReviewStatusPending,
+	}
+	if err := repository.CreateReview(&review); err != nil {
+		if errors.Is(err, repository.ErrReviewExists) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("erro ao salvar avaliação: %w", err)
+	}
+
+	return &review, nil
+}
+
+// uploadReviewPhotos envia as fotos da avaliação e retorna as URLs na ordem recebida
+func uploadReviewPhotos(photos []*multipart.FileHeader) ([]string, error) {
+	if len(photos) == 0 {
+		return nil, nil
+	}
+	if uploadService == nil {
+		InitUploadService(5)
+	}
+
+	urls := make([]string, 0, len(photos))
+	for i, photo := range photos {
+		result := uploadService.uploadSingleFile(photo, i)
+		if result.Error != nil {
+			return nil, fmt.Errorf("erro ao enviar foto %d: %w", i+1, result.Error)
+		}
+		urls = append(urls, result.URL)
+	}
+	return urls, nil
+}
+
+// GetProductReviews retorna as avaliações aprovadas de um produto, paginadas
+func GetProductReviews(productID uint, page, limit int) (*model.PaginatedResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	reviews, total, err := repository.GetApprovedReviewsWithCount(productID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Data:     reviews,
+		Metadata: model.CalculatePagination(page, limit, total),
+	}, nil
+}
+
+// GetUserReviews retorna as avaliações do usuário logado, com o status de moderação
+func GetUserReviews(userID uint) ([]model.Review, error) {
+	return repository.GetUserReviews(userID)
+}
+
+// GetReviewQueue lista avaliações para o admin; o padrão é a fila de pendentes
+func GetReviewQueue(status string, page, limit int) (*model.PaginatedResponse, error) {
+	reviewStatus := model.ReviewStatus(status)
+	switch {
+	case status == "":
+		reviewStatus = model.ReviewStatusPending
+	case status == "all":
+		reviewStatus = ""
+	case !reviewStatus.IsValid():
+		return nil, errors.New("status inválido: use pending, approved, rejected ou all")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	reviews, total, err := repository.GetReviewsByStatusWithCount(reviewStatus, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PaginatedResponse{
+		Data:     reviews,
+		Metadata: model.CalculatePagination(page, limit, total),
+	}, nil
+}
+
+/**
+ * ModerateReview approves or rejects a review and refreshes the product's
+ * rating. An approved review can still be rejected later and vice versa.
+ */
+func ModerateReview(reviewID uint, status model.ReviewStatus) (*model.Review, error) {
+	if status != model.ReviewStatusApproved && status != model.ReviewStatusRejected {
+		return nil, errors.New("status de moderação inválido")
+	}
+
+	review, err := repository.GetReviewByID(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		return nil, errors.New("avaliação não encontrada")
+	}
+
+	if err := repository.ModerateReview(review, status); err != nil {
+		return nil, fmt.Errorf("erro ao moderar avaliação: %w", err)
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	return review, nil
+}
+
+// ReplyReview grava (ou apaga, com texto vazio) a resposta da loja a uma avaliação
+func ReplyReview(reviewID uint, reply string) (*model.Review, error) {
+	reply = strings.TrimSpace(reply)
+	if utf8.RuneCountInString(reply) > MaxReviewReplyLength {
+		return nil, fmt.Errorf("a resposta deve ter no máximo %d caracteres", MaxReviewReplyLength)
+	}
+
+	review, err := repository.GetReviewByID(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		return nil, errors.New("avaliação não encontrada")
+	}
+
+	if err := repository.ReplyReview(review, reply); err != nil {
+		return nil, fmt.Errorf("erro ao responder avaliação: %w", err)
+	}
+
+	return review, nil
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
+)
+
+/**
+ * generateUniqueSlug builds a slug from the given name and appends a numeric
+ * suffix ("-2", "-3", ...) until no other entity of the same type uses it.
+ *
+ * @param entityType - model.SlugEntityProduct or model.SlugEntityCategory
+ * @param name - The display name to derive the slug from
+ * @param excludeID - ID of the entity being saved (0 when creating)
+ * @returns - A slug that is free to use
+ */
+func generateUniqueSlug(entityType, name string, excludeID uint) (string, error) {
+	return generateUniqueSlugReserving(entityType, name, excludeID, nil)
+}
+
+/**
+ * generateUniqueSlugReserving works like generateUniqueSlug but also avoids
+ * slugs in the reserved set, and adds the chosen slug to it. Used by batch
+ * operations that save several entities in a single transaction.
+ */
+func generateUniqueSlugReserving(entityType, name string, excludeID uint, reserved map[string]bool) (string, error) {
+	base := util.Slugify(name)
+	if base == "" {
+		base = entityType
+	}
+
+	candidate := base
+	for i := 2; ; i++ {
+		taken := reserved[candidate]
+		if !taken {
+			var err error
+			taken, err = repository.SlugTaken(entityType, candidate, excludeID)
+			if err != nil {
+				return "", err
+			}
+		}
+		if !taken {
+			if reserved != nil {
+				reserved[candidate] = true
+			}
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+/**
+ * renameSlug returns the slug an entity should use after its name changed.
+ * The previous slug is recorded in the history by the repository, in the
+ * same transaction that saves the entity, so old links keep working.
+ *
+ * @param entityType - model.SlugEntityProduct or model.SlugEntityCategory
+ * @param entityID - The entity being renamed
+ * @param currentSlug - The slug currently stored for the entity
+ * @param newName - The new display name
+ */
+func renameSlug(entityType string, entityID uint, currentSlug, newName string) (string, error) {
+	if currentSlug != "" && util.Slugify(newName) == currentSlug {
+		return currentSlug, nil
+	}
+
+	newSlug, err := generateUniqueSlug(entityType, newName, entityID)
+	if err != nil {
+		return "", err
+	}
+	return newSlug, nil
+}
+
+/**
+ * GetProductBySlug looks a product up by its current slug, with its texts in
+ * the given locale. When the slug only exists in the history, the product is
+ * nil and redirectSlug holds the slug the client should be redirected to.
+ */
+func GetProductBySlug(slug, locale string) (product *model.Product, redirectSlug string, err error) {
+	product, err = repository.GetProductBySlug(slug)
+	if err != nil {
+		return nil, "", err
+	}
+	if product != nil {
+		product.Localize(locale)
+		setBreadcrumbs([]*model.Product{product}, locale)
+		return product, "", nil
+	}
+
+	entry, err := repository.FindSlugHistory(model.SlugEntityProduct, slug)
+	if err != nil {
+		return nil, "", err
+	}
+	if entry == nil {
+		return nil, "", errors.New("produto não encontrado")
+	}
+
+	current, err := repository.GetVisibleProductByID(entry.EntityID)
+	if err != nil {
+		return nil, "", err
+	}
+	if current == nil {
+		return nil, "", errors.New("produto não encontrado")
+	}
+	return nil, current.Slug, nil
+}
+
+/**
+ * GetCategoryBySlug looks a category up by its current slug, following the
+ * same locale and redirect rules as GetProductBySlug.
+ */
+func GetCategoryBySlug(slug, locale string) (category *model.Category, redirectSlug string, err error) {
+	category, err = repository.GetCategoryBySlug(slug)
+	if err != nil {
+		return nil, "", err
+	}
+	if category != nil {
+		// Categorias ocultas (ou dentro de uma oculta) não aparecem na vitrine
+		visible, err := GetCategories(locale)
+		if err != nil {
+			return nil, "", err
+		}
+		for i := range visible {
+			if visible[i].ID == category.ID {
+				category.Localize(locale)
+				category.ProductCount = visible[i].ProductCount
+				return category, "", nil
+			}
+		}
+		return nil, "", errors.New("categoria não encontrada")
+	}
+
+	entry, err := repository.FindSlugHistory(model.SlugEntityCategory, slug)
+	if err != nil {
+		return nil, "", err
+	}
+	if entry == nil {
+		return nil, "", errors.New("categoria não encontrada")
+	}
+
+	current, err := repository.GetCategoryByID(entry.EntityID)
+	if err != nil {
+		return nil, "", err
+	}
+	if current == nil {
+		return nil, "", errors.New("categoria não encontrada")
+	}
+	return nil, current.Slug, nil
+}
+
+/**
+ * BackfillSlugs generates slugs for products and categories created before
+ * slugs existed. It is safe to run on every startup.
+ */
+func BackfillSlugs() {
+	categories, err := repository.GetCategoriesWithoutSlug()
+	if err != nil {
+		log.Printf("Erro ao buscar categorias sem slug: %v", err)
+		return
+	}
+	for _, category := range categories {
+		slug, err := generateUniqueSlug(model.SlugEntityCategory, category.Name, category.ID)
+		if err == nil {
+			err = repository.UpdateCategorySlug(category.ID, slug)
+		}
+		if err != nil {
+			log.Printf("Erro ao gerar slug da categoria %d: %v", category.ID, err)
+		}
+	}
+
+	products, err := repository.GetProductsWithoutSlug()
+	if err != nil {
+		log.Printf("Erro ao buscar produtos sem slug: %v", err)
+		return
+	}
+	for _, product := range products {
+		slug, err := generateUniqueSlug(model.SlugEntityProduct, product.Name, product.ID)
+		if err == nil {
+			err = repository.UpdateProductSlug(product.ID, slug)
+		}
+		if err != nil {
+			log.Printf("Erro ao gerar slug do produto %d: %v", product.ID, err)
+		}
+	}
+
+	if len(categories) > 0 || len(products) > 0 {
+		cacheService := &CacheService{}
+		cacheService.InvalidateAllCache()
+		log.Printf("Slugs gerados: %d categorias, %d produtos", len(categories), len(products))
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
+)
+
+// MaxProductTags limita quantas tags um produto pode ter
+const MaxProductTags = 20
+
+// GetTags retorna todas as tags com a contagem de produtos visíveis
+func GetTags() ([]model.TagWithCount, error) {
+	return repository.GetTagsWithCount()
+}
+
+/**
+ * resolveTags turns the tag names sent with a product into tag records.
+ * Tags that do not exist yet come back unsaved (ID zero) and are created by
+ * the repository in the same transaction as the product change. Names are
+ * matched by slug, so "Bebê" and "bebe" refer to the same tag; duplicates
+ * are dropped.
+ *
+ * @param names - Tag names as typed by the admin
+ * @returns - The tags in the order they were first mentioned
+ */
+func resolveTags(names []string) ([]model.Tag, error) {
+	slugs := []string{}
+	displayNames := map[string]string{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		slug := util.Slugify(name)
+		if slug == "" {
+			continue
+		}
+		if _, seen := displayNames[slug]; seen {
+			continue
+		}
+		displayNames[slug] = name
+		slugs = append(slugs, slug)
+	}
+	if len(slugs) > MaxProductTags {
+		return nil, fmt.Errorf("%w: um produto pode ter no máximo %d tags", ErrInvalidProduct, MaxProductTags)
+	}
+
+	existing, err := repository.GetTagsBySlugs(slugs)
+	if err != nil {
+		return nil, err
+	}
+	bySlug := make(map[string]model.Tag, len(existing))
+	for _, tag := range existing {
+		bySlug[tag.Slug] = tag
+	}
+
+	tags := make([]model.Tag, 0, len(slugs))
+	for _, slug := range slugs {
+		tag, ok := bySlug[slug]
+		if !ok {
+			tag = model.Tag{Name: displayNames[slug], Slug: slug}
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// CreateTag cria uma tag avulsa
+func CreateTag(name string) (*model.Tag, error) {
+	name = strings.TrimSpace(name)
+	slug := util.Slugify(name)
+	if slug == "" {
+		return nil, errors.New("nome da tag é obrigatório")
+	}
+
+	existing, err := repository.GetTagsBySlugs([]string{slug})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("já existe a tag %q", existing[0].Name)
+	}
+
+	tag := model.Tag{Name: name, Slug: slug}
+	if err := repository.CreateTag(&tag); err != nil {
+		return nil, fmt.Errorf("erro ao criar tag: %w", err)
+	}
+	return &tag, nil
+}
+
+// RenameTag altera o nome (e o slug) de uma tag
+func RenameTag(tagID uint, name string) (*model.Tag, error) {
+	tag, err := repository.GetTagByID(tagID)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, errors.New("tag não encontrada")
+	}
+
+	name = strings.TrimSpace(name)
+	slug := util.Slugify(name)
+	if slug == "" {
+		return nil, errors.New("nome da tag é obrigatório")
+	}
+	if slug != tag.Slug {
+		existing, err := repository.GetTagsBySlugs([]string{slug})
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) > 0 {
+			return nil, fmt.Errorf("já existe a tag %q; use o merge para unificá-las", existing[0].Name)
+		}
+	}
+
+	tag.Name = name
+	tag.Slug = slug
+	if err := repository.UpdateTag(tag); err != nil {
+		return nil, fmt.Errorf("erro ao atualizar tag: %w", err)
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	return tag, nil
+}
+
+// DeleteTag remove uma tag de todos os produtos e a apaga
+func DeleteTag(tagID uint) error {
+	tag, err := repository.GetTagByID(tagID)
+	if err != nil {
+		return err
+	}
+	if tag == nil {
+		return errors.New("tag não encontrada")
+	}
+
+	if err := repository.DeleteTag(tagID); err != nil {
+		return fmt.Errorf("erro ao deletar tag: %w", err)
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	return nil
+}
+
+/**
+ * MergeTags moves every product tagged with the source tag to the target tag
+ * and deletes the source, e.g. to unify "presentes" into "presente".
+ *
+ * @param sourceID - The tag that will disappear
+ * @param targetID - The tag that is kept
+ * @returns - The target tag
+ */
+func MergeTags(sourceID, targetID uint) (*model.Tag, error) {
+	if sourceID == targetID {
+		return nil, errors.New("não é possível unificar uma tag com ela mesma")
+	}
+
+	source, err := repository.GetTagByID(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, errors.New("tag de origem não encontrada")
+	}
+	target, err := repository.GetTagByID(targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, errors.New("tag de destino não encontrada")
+	}
+
+	if err := repository.MergeTags(sourceID, targetID); err != nil {
+		return nil, fmt.Errorf("erro ao unificar tags: %w", err)
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	return target, nil
+}
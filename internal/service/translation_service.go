@@ -0,0 +1,72 @@
+package service
+
+import (
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+)
+
+// MissingTranslation é um registro com campos ainda sem tradução em algum idioma
+type MissingTranslation struct {
+	EntityType string              `json:"entityType"`
+	ID         uint                `json:"id"`
+	Name       string              `json:"name"`
+	Missing    map[string][]string `json:"missing"`
+}
+
+// MissingTranslationsReport agrupa as traduções pendentes e o total de registros pendentes por idioma
+type MissingTranslationsReport struct {
+	Locales []string             `json:"locales"`
+	Totals  map[string]int       `json:"totals"`
+	Items   []MissingTranslation `json:"items"`
+}
+
+/**
+ * GetMissingTranslations lists the products, categories and promotions
+ * that have default-locale text without a translation.
+ *
+ * @param locale - A single non-default locale to check, or empty for all of them
+ */
+func GetMissingTranslations(locale string) (*MissingTranslationsReport, error) {
+	locales := model.SupportedLocales[1:]
+	if locale != "" {
+		locales = []string{locale}
+	}
+
+	report := &MissingTranslationsReport{Locales: locales, Totals: map[string]int{}, Items: []MissingTranslation{}}
+	add := func(entityType string, id uint, name string, translations model.Translations, texts map[string]string) {
+		missing := translations.Missing(locales, texts)
+		if len(missing) == 0 {
+			return
+		}
+		for l := range missing {
+			report.Totals[l]++
+		}
+		report.Items = append(report.Items, MissingTranslation{EntityType: entityType, ID: id, Name: name, Missing: missing})
+	}
+
+	categories, err := repository.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+	for i := range categories {
+		add("category", categories[i].ID, categories[i].Name, categories[i].Translations, categories[i].TranslatableTexts())
+	}
+
+	products, err := repository.GetProducts()
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		add("product", products[i].ID, products[i].Name, products[i].Translations, products[i].TranslatableTexts())
+	}
+
+	promotions, err := repository.GetPromotions()
+	if err != nil {
+		return nil, err
+	}
+	for i := range promotions {
+		add("promotion", promotions[i].ID, promotions[i].Name, promotions[i].Translations, promotions[i].TranslatableTexts())
+	}
+
+	return report, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+)
+
+// DefaultTrashRetentionDays é o número de dias que um item fica na lixeira quando não configurado
+const DefaultTrashRetentionDays = 30
+
+/**
+ * TrashCategory is a deleted category as listed in the trash, with the number
+ * of products that were deleted together with it and can be restored along.
+ */
+type TrashCategory struct {
+	model.Category
+	DeletedProducts int64 `json:"deletedProducts"`
+}
+
+// Trash agrupa os itens da lixeira
+type Trash struct {
+	Products   []model.Product `json:"products"`
+	Categories []TrashCategory `json:"categories"`
+}
+
+/**
+ * trashRetentionDays reads TRASH_RETENTION_DAYS. Zero disables the automatic
+ * purge; invalid or missing values fall back to the default.
+ */
+func trashRetentionDays() int {
+	value := os.Getenv("TRASH_RETENTION_DAYS")
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+		return n
+	}
+	return DefaultTrashRetentionDays
+}
+
+// GetTrash lista produtos e categorias deletados
+func GetTrash() (*Trash, error) {
+	products, err := repository.GetDeletedProducts()
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := repository.GetDeletedCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	trash := &Trash{Products: products, Categories: make([]TrashCategory, 0, len(categories))}
+	for _, category := range categories {
+		count, err := repository.CountProductsDeletedWithCategory(category.ID, category.DeletedAt.Time)
+		if err != nil {
+			return nil, err
+		}
+		trash.Categories = append(trash.Categories, TrashCategory{Category: category, DeletedProducts: count})
+	}
+
+	return trash, nil
+}
+
+// RestoreProduct tira um produto da lixeira
+func RestoreProduct(productID uint) error {
+	product, err := repository.GetDeletedProductByID(productID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return errors.New("produto não encontrado na lixeira")
+	}
+
+	category, err := repository.GetCategoryByID(product.CategoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return errors.New("a categoria do produto está na lixeira; restaure a categoria primeiro")
+	}
+
+	if err := repository.RestoreProduct(productID); err != nil {
+		return err
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateProductCache()
+
+	return nil
+}
+
+/**
+ * RestoreCategory takes a category out of the trash. With withProducts set,
+ * the products deleted together with it are restored too.
+ *
+ * @returns - Number of products restored and number still in the trash
+ */
+func RestoreCategory(categoryID uint, withProducts bool) (int64, int64, error) {
+	category, err := repository.GetDeletedCategoryByID(categoryID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if category == nil {
+		return 0, 0, errors.New("categoria não encontrada na lixeira")
+	}
+
+	deletedWith, err := repository.CountProductsDeletedWithCategory(categoryID, category.DeletedAt.Time)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	restored, err := repository.RestoreCategory(categoryID, category.DeletedAt.Time, withProducts)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	cacheService := &CacheService{}
+	cacheService.InvalidateCategoryCache()
+	if restored > 0 {
+		cacheService.InvalidateProductCache()
+	}
+
+	return restored, deletedWith - restored, nil
+}
+
+// PurgeProduct remove permanentemente um produto da lixeira
+func PurgeProduct(productID uint) error {
+	product, err := repository.GetDeletedProductByID(productID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return errors.New("produto não encontrado na lixeira")
+	}
+	images, err := repository.PurgeProduct(productID)
+	if err != nil {
+		return err
+	}
+
+	deleteImageFilesIfUnused(images)
+	return nil
+}
+
+// deleteImageFilesIfUnused apaga, depois do commit, os arquivos locais que não são usados por nenhum outro produto ou categoria
+func deleteImageFilesIfUnused(imagePaths []string) {
+	for _, imagePath := range imagePaths {
+		if _, err := deleteImageFileIfUnused(imagePath); err != nil {
+			log.Printf("Erro ao remover arquivo da imagem %s: %v", imagePath, err)
+		}
+	}
+}
+
+// PurgeCategory remove permanentemente uma categoria da lixeira e seus produtos deletados
+func PurgeCategory(categoryID uint) error {
+	category, err := repository.GetDeletedCategoryByID(categoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return errors.New("categoria não encontrada na lixeira")
+	}
+	images, err := repository.PurgeCategory(categoryID)
+	if err != nil {
+		return err
+	}
+
+	deleteImageFilesIfUnused(images)
+	return nil
+}
+
+/**
+ * StartTrashPurger periodically removes items that have been in the trash for
+ * longer than TRASH_RETENTION_DAYS. Does nothing when retention is 0.
+ *
+ * @param interval - How often expired items are purged
+ */
+func StartTrashPurger(interval time.Duration) {
+	days := trashRetentionDays()
+	if days == 0 {
+		log.Println("Limpeza automática da lixeira desativada (TRASH_RETENTION_DAYS=0)")
+		return
+	}
+
+	go func() {
+		for {
+			before := time.Now().UTC().AddDate(0, 0, -days)
+			result, err := repository.PurgeDeletedBefore(before)
+			deleteImageFilesIfUnused(result.ImageUrls)
+			if err != nil {
+				log.Printf("Erro ao limpar a lixeira: %v", err)
+			} else if result.Products > 0 || result.Categories > 0 {
+				log.Printf("Lixeira: %d produtos e %d categorias removidos permanentemente", result.Products, result.Categories)
+			}
+
+			time.Sleep(interval)
+		}
+	}()
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/repository"
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/util"
+)
+
+// DefaultViewDedupMinutes é a janela em que visitas repetidas do mesmo visitante contam uma vez só
+const DefaultViewDedupMinutes = 30
+
+// maxRecentViews limita quantos produtos recentes de cada visitante entram no cálculo de co-visualizações
+const maxRecentViews = 10
+
+type viewCounterKey struct {
+	productID uint
+	day       time.Time
+}
+
+type viewCounter struct {
+	detail int64
+	image  int64
+}
+
+type coViewKey struct {
+	productID        uint
+	relatedProductID uint
+}
+
+type recentView struct {
+	productID uint
+	at        time.Time
+}
+
+/**
+ * viewTracker keeps deduplicated view counters in memory and writes them to
+ * the database in batches. Visitors are identified by a hash of IP and
+ * User-Agent, so no cookie or personal data is stored.
+ */
+type viewTracker struct {
+	mu      sync.Mutex
+	window  time.Duration
+	seen    map[string]time.Time // visitante + produto + tipo -> última visita contada
+	recent  map[string][]recentView
+	views   map[viewCounterKey]*viewCounter
+	coViews map[coViewKey]int64
+}
+
+var tracker = newViewTracker(viewDedupWindow())
+
+func newViewTracker(window time.Duration) *viewTracker {
+	return &viewTracker{
+		window:  window,
+		seen:    map[string]time.Time{},
+		recent:  map[string][]recentView{},
+		views:   map[viewCounterKey]*viewCounter{},
+		coViews: map[coViewKey]int64{},
+	}
+}
+
+// viewDedupWindow lê a janela de deduplicação de VIEW_DEDUP_MINUTES
+func viewDedupWindow() time.Duration {
+	minutes := DefaultViewDedupMinutes
+	if n, err := strconv.Atoi(os.Getenv("VIEW_DEDUP_MINUTES")); err == nil && n > 0 {
+		minutes = n
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// visitorID gera um identificador anônimo do visitante
+func visitorID(clientIP, userAgent string) string {
+	sum := sha256.Sum256([]byte(clientIP + "|" + userAgent))
+	return hex.EncodeToString(sum[:12])
+}
+
+/**
+ * TrackProductView records a view of a product. Robots are ignored and the
+ * same visitor is counted once per product and kind inside the dedup window.
+ * Detail views also feed the co-view pairs used by related products.
+ *
+ * @param productID - The viewed product
+ * @param kind - model.ViewKindDetail or model.ViewKindImage
+ * @param clientIP - The visitor's IP
+ * @param userAgent - The visitor's User-Agent
+ */
+func TrackProductView(productID uint, kind model.ViewKind, clientIP, userAgent string) {
+	if productID == 0 || util.IsBotUserAgent(userAgent) {
+		return
+	}
+	tracker.record(productID, kind, visitorID(clientIP, userAgent), time.Now().UTC())
+}
+
+func (t *viewTracker) record(productID uint, kind model.ViewKind, visitor string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seenKey := visitor + "|" + strconv.FormatUint(uint64(productID), 10) + "|" + string(kind)
+	if last, ok := t.seen[seenKey]; ok && now.Sub(last) < t.window {
+		return
+	}
+	t.seen[seenKey] = now
+
+	key := viewCounterKey{productID: productID, day: now.Truncate(24 * time.Hour)}
+	counter := t.views[key]
+	if counter == nil {
+		counter = &viewCounter{}
+		t.views[key] = counter
+	}
+	if kind == model.ViewKindImage {
+		counter.image++
+		return
+	}
+	counter.detail++
+
+	// Co-visualizações: produtos vistos pelo mesmo visitante dentro da janela
+	recent := t.recent[visitor][:0]
+	for _, r := range t.recent[visitor] {
+		if now.Sub(r.at) >= t.window || r.productID == productID {
+			continue
+		}
+		recent = append(recent, r)
+		t.coViews[coViewKey{productID: productID, relatedProductID: r.productID}]++
+		t.coViews[coViewKey{productID: r.productID, relatedProductID: productID}]++
+	}
+	recent = append(recent, recentView{productID: productID, at: now})
+	if len(recent) > maxRecentViews {
+		recent = recent[len(recent)-maxRecentViews:]
+	}
+	t.recent[visitor] = recent
+}
+
+// drain retira os contadores pendentes e descarta entradas de deduplicação vencidas
+func (t *viewTracker) drain(now time.Time) ([]model.ProductViewDaily, []model.ProductCoView) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	views := make([]model.ProductViewDaily, 0, len(t.views))
+	for key, counter := range t.views {
+		views = append(views, model.ProductViewDaily{
+			ProductID:   key.productID,
+			Day:         key.day,
+			DetailViews: counter.detail,
+			ImageViews:  counter.image,
+		})
+	}
+	coViews := make([]model.ProductCoView, 0, len(t.coViews))
+	for key, count := range t.coViews {
+		coViews = append(coViews, model.ProductCoView{
+			ProductID:        key.productID,
+			RelatedProductID: key.relatedProductID,
+			Count:            count,
+			UpdatedAt:        now,
+		})
+	}
+	t.views = map[viewCounterKey]*viewCounter{}
+	t.coViews = map[coViewKey]int64{}
+
+	for key, last := range t.seen {
+		if now.Sub(last) >= t.window {
+			delete(t.seen, key)
+		}
+	}
+	for visitor, recent := range t.recent {
+		if len(recent) == 0 || now.Sub(recent[len(recent)-1].at) >= t.window {
+			delete(t.recent, visitor)
+		}
+	}
+
+	return views, coViews
+}
+
+// requeue devolve à memória contadores que não puderam ser gravados, somando-os aos acumulados desde então
+func (t *viewTracker) requeue(views []model.ProductViewDaily, coViews []model.ProductCoView) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, view := range views {
+		key := viewCounterKey{productID: view.ProductID, day: view.Day}
+		counter := t.views[key]
+		if counter == nil {
+			counter = &viewCounter{}
+			t.views[key] = counter
+		}
+		counter.detail += view.DetailViews
+		counter.image += view.ImageViews
+	}
+	for _, coView := range coViews {
+		t.coViews[coViewKey{productID: coView.ProductID, relatedProductID: coView.RelatedProductID}] += coView.Count
+	}
+}
+
+// FlushProductViews grava os contadores pendentes no banco; se a gravação falhar, eles voltam para a próxima tentativa
+func FlushProductViews() {
+	views, coViews := tracker.drain(time.Now().UTC())
+	if err := repository.SaveViewBatch(views, coViews); err != nil {
+		log.Printf("Erro ao gravar visualizações de produtos: %v", err)
+		tracker.requeue(views, coViews)
+	}
+}
+
+/**
+ * StartViewFlusher writes the buffered view counters to the database in the
+ * background. A failed write keeps the counters for the next tick; call
+ * FlushProductViews once more on shutdown so the last minute is not lost.
+ *
+ * @param interval - How often the buffer is flushed
+ */
+func StartViewFlusher(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			FlushProductViews()
+		}
+	}()
+}
+
+// ViewReport é o relatório de visualizações do painel admin
+type ViewReport struct {
+	Since    time.Time                 `json:"since"`
+	Days     []model.ViewReportDay     `json:"days"`
+	Products []model.ViewReportProduct `json:"products"`
+}
+
+// GetViewReport retorna os totais diários e os produtos mais vistos dos últimos dias
+func GetViewReport(days, limit int) (*ViewReport, error) {
+	if days < 1 {
+		days = model.PopularityWindowDays
+	}
+	if days > 365 {
+		days = 365
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+
+	daily, err := repository.GetDailyViewTotals(since)
+	if err != nil {
+		return nil, err
+	}
+	products, err := repository.GetTopViewedProducts(since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ViewReport{Since: since, Days: daily, Products: products}, nil
+}
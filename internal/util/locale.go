@@ -0,0 +1,71 @@
+package util
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jpeccia/lariharumi_croche_backend_go/internal/model"
+)
+
+/**
+ * ResolveLocale picks the response locale. An explicit lang parameter wins,
+ * then the Accept-Language preferences in quality order; anything that does
+ * not match a supported locale falls back to pt-BR. Regional variants match
+ * their language, so "en-US" gives "en" and "pt-PT" gives "pt-BR".
+ *
+ * @param lang - The lang query parameter, may be empty
+ * @param acceptLanguage - The Accept-Language header, may be empty
+ */
+func ResolveLocale(lang, acceptLanguage string) string {
+	if locale, ok := matchLocale(lang); ok {
+		return locale
+	}
+
+	type preference struct {
+		tag     string
+		quality float64
+	}
+	var preferences []preference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if tag != "" && tag != "*" && quality > 0 {
+			preferences = append(preferences, preference{tag: tag, quality: quality})
+		}
+	}
+	sort.SliceStable(preferences, func(i, j int) bool { return preferences[i].quality > preferences[j].quality })
+
+	for _, p := range preferences {
+		if locale, ok := matchLocale(p.tag); ok {
+			return locale
+		}
+	}
+	return model.DefaultLocale
+}
+
+// matchLocale encontra o idioma suportado que corresponde à tag, exata ou pelo idioma principal
+func matchLocale(tag string) (string, bool) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" {
+		return "", false
+	}
+	for _, locale := range model.SupportedLocales {
+		if strings.EqualFold(locale, tag) {
+			return locale, true
+		}
+	}
+	language, _, _ := strings.Cut(tag, "-")
+	for _, locale := range model.SupportedLocales {
+		primary, _, _ := strings.Cut(locale, "-")
+		if strings.EqualFold(primary, language) {
+			return locale, true
+		}
+	}
+	return "", false
+}
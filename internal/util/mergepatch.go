@@ -0,0 +1,73 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidMergePatch indica que o corpo enviado não é um objeto JSON
+var ErrInvalidMergePatch = errors.New("o corpo deve ser um objeto JSON (JSON Merge Patch)")
+
+/**
+ * MergePatch applies a JSON Merge Patch (RFC 7386) to a JSON document: keys
+ * present in the patch replace the document's values, null removes a key and
+ * nested objects are merged recursively. Arrays are always replaced.
+ *
+ * @param doc - The current document
+ * @param patch - The patch sent by the client; must be a JSON object
+ * @returns - The patched document and the top-level keys the patch touched
+ */
+func MergePatch(doc, patch []byte) ([]byte, map[string]bool, error) {
+	var target interface{}
+	if err := decodeJSON(doc, &target); err != nil {
+		return nil, nil, err
+	}
+
+	var changes interface{}
+	if err := decodeJSON(patch, &changes); err != nil {
+		return nil, nil, ErrInvalidMergePatch
+	}
+	fields, ok := changes.(map[string]interface{})
+	if !ok {
+		return nil, nil, ErrInvalidMergePatch
+	}
+
+	touched := make(map[string]bool, len(fields))
+	for key := range fields {
+		touched[key] = true
+	}
+
+	merged, err := json.Marshal(mergeValue(target, fields))
+	if err != nil {
+		return nil, nil, err
+	}
+	return merged, touched, nil
+}
+
+// mergeValue aplica recursivamente o patch sobre o valor atual
+func mergeValue(target, patch interface{}) interface{} {
+	fields, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = map[string]interface{}{}
+	}
+	for key, value := range fields {
+		if value == nil {
+			delete(object, key)
+			continue
+		}
+		object[key] = mergeValue(object[key], value)
+	}
+	return object
+}
+
+// decodeJSON decodifica mantendo números como json.Number, sem perder precisão de IDs
+func decodeJSON(data []byte, dest interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(dest)
+}
@@ -0,0 +1,181 @@
+package util
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var priceNumberRegex = regexp.MustCompile(`\d+(?:[.,]\d+)*`)
+
+/**
+ * ParsePriceRange extracts the lowest and highest amounts from a free-form
+ * price such as "R$ 50,00", "35 - 60" or "R$ 1.200,00 a R$ 1.500,00".
+ * Both Brazilian (1.234,56) and international (1,234.56) notations are accepted.
+ *
+ * @param price - The price text as stored in Product.PriceRange
+ * @returns - Minimum and maximum amounts, and whether any amount was found
+ */
+func ParsePriceRange(price string) (float64, float64, bool) {
+	var values []float64
+	for _, match := range priceNumberRegex.FindAllString(price, -1) {
+		if value, ok := parseAmount(match); ok {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return 0, 0, false
+	}
+
+	min, max := values[0], values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return min, max, true
+}
+
+// parseAmount converte um número com separadores de milhar/decimal ambíguos
+func parseAmount(s string) (float64, bool) {
+	lastDot := strings.LastIndex(s, ".")
+	lastComma := strings.LastIndex(s, ",")
+
+	var decimalSep byte
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimalSep = s[max(lastDot, lastComma)]
+	case lastComma >= 0:
+		// "50,00" é decimal; "1,200" é milhar
+		if len(s)-lastComma-1 != 3 {
+			decimalSep = ','
+		}
+	case lastDot >= 0:
+		// "50.5" é decimal; "1.200" é milhar
+		if len(s)-lastDot-1 != 3 {
+			decimalSep = '.'
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			b.WriteByte(s[i])
+		case s[i] == decimalSep && i == strings.LastIndexByte(s, decimalSep):
+			b.WriteByte('.')
+		}
+	}
+
+	value, err := strconv.ParseFloat(b.String(), 64)
+	return value, err == nil
+}
+
+/**
+ * AdjustPriceText applies a percentage to every amount found in a free-form
+ * price, keeping the surrounding text: "R$ 50,00 a R$ 80,00" adjusted by 10
+ * becomes "R$ 55,00 a R$ 88,00". Results are rounded to cents and written in
+ * Brazilian notation.
+ *
+ * @param price - The price text as stored in Product.PriceRange
+ * @param percent - The adjustment, e.g. 10 for +10% or -5 for -5%
+ * @returns - The adjusted text, and whether any amount was found
+ */
+func AdjustPriceText(price string, percent float64) (string, bool) {
+	matches := priceNumberRegex.FindAllString(price, -1)
+	adjusted := make(map[string]float64, len(matches))
+	// Centavos aparecem em todos os valores se algum já os tinha ou passou a ter
+	cents := false
+	for _, match := range matches {
+		value, ok := parseAmount(match)
+		if !ok {
+			continue
+		}
+		value = math.Round(value*(1+percent/100)*100) / 100
+		adjusted[match] = value
+		if len(match) > 3 && (match[len(match)-3] == ',' || match[len(match)-3] == '.') || value != math.Trunc(value) {
+			cents = true
+		}
+	}
+	if len(adjusted) == 0 {
+		return price, false
+	}
+
+	return priceNumberRegex.ReplaceAllStringFunc(price, func(match string) string {
+		if value, ok := adjusted[match]; ok {
+			return FormatBRL(value, cents)
+		}
+		return match
+	}), true
+}
+
+// FormatBRL formata um valor na notação brasileira (1.234,56), com ou sem centavos
+func FormatBRL(value float64, cents bool) string {
+	text := strconv.FormatFloat(math.Abs(value), 'f', 2, 64)
+	integer, fraction := text[:len(text)-3], text[len(text)-2:]
+
+	var b strings.Builder
+	if value < 0 {
+		b.WriteByte('-')
+	}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+	if cents {
+		b.WriteString("," + fraction)
+	}
+	return b.String()
+}
+
+/**
+ * RoundToStep rounds a value to a multiple of step, e.g. 0.01 for cents or
+ * 0.5 for half units. Mode is "up", "down" or anything else for nearest.
+ *
+ * @param value - The amount to round
+ * @param step - The rounding increment, ignored when not positive
+ * @param mode - The rounding direction
+ */
+func RoundToStep(value, step float64, mode string) float64 {
+	if step <= 0 {
+		return value
+	}
+	// Elimina ruído de ponto flutuante antes de arredondar para cima ou para baixo
+	units := math.Round(value/step*1e6) / 1e6
+	switch mode {
+	case "up":
+		units = math.Ceil(units)
+	case "down":
+		units = math.Floor(units)
+	default:
+		units = math.Round(units)
+	}
+	return math.Round(units*step*1e6) / 1e6
+}
+
+// FormatAmount formata um valor na notação internacional (1,234.56) com as casas decimais pedidas
+func FormatAmount(value float64, decimals int) string {
+	text := strconv.FormatFloat(math.Abs(value), 'f', decimals, 64)
+	integer, fraction, _ := strings.Cut(text, ".")
+
+	var b strings.Builder
+	if value < 0 {
+		b.WriteByte('-')
+	}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString("." + fraction)
+	}
+	return b.String()
+}
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+/**
+ * Slugify converts a display name into a URL-friendly slug.
+ * Accents are stripped ("Crochê" -> "croche") and every run of
+ * non-alphanumeric characters becomes a single hyphen.
+ *
+ * @param name - The name to convert
+ * @returns - The lowercase slug, or an empty string if nothing remains
+ */
+func Slugify(name string) string {
+	var b strings.Builder
+	lastHyphen := true
+
+	for _, r := range norm.NFD.String(strings.ToLower(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			lastHyphen = false
+		case !lastHyphen:
+			b.WriteByte('-')
+			lastHyphen = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
@@ -0,0 +1,37 @@
+package util
+
+import (
+	"os"
+	"strings"
+)
+
+/**
+ * botAgentMarkers are fragments found in the User-Agent of crawlers, link
+ * previews, uptime monitors and HTTP libraries. The keep-alive cron that
+ * pings the Render instance falls in the last group.
+ */
+var botAgentMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "preview", "facebookexternalhit", "whatsapp",
+	"headless", "lighthouse", "monitor", "uptime", "pingdom", "cron", "keep-alive", "keepalive",
+	"render", "curl", "wget", "python", "go-http-client", "postman",
+}
+
+// IsBotUserAgent indica se o User-Agent pertence a um robô; agentes vazios também contam como robô
+func IsBotUserAgent(userAgent string) bool {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return true
+	}
+	for _, marker := range botAgentMarkers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	// Marcadores extras separados por vírgula, ex.: o User-Agent de um monitor próprio
+	for _, marker := range strings.Split(os.Getenv("BOT_USER_AGENTS"), ",") {
+		if marker = strings.ToLower(strings.TrimSpace(marker)); marker != "" && strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	return false
+}
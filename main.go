@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/jpeccia/lariharumi_croche_backend_go/config"
 	"github.com/jpeccia/lariharumi_croche_backend_go/internal/router"
@@ -12,9 +18,26 @@ import (
 func main() {
 	config.ConnectDB()
 
+	// Gera slugs para registros criados antes da existência do campo
+	service.BackfillSlugs()
+
+	// Comandos de manutenção (migrate, backup, restore) não sobem o servidor
+	if runCommand(os.Args[1:]) {
+		return
+	}
+
 	// Inicializa o serviço de upload assíncrono
 	service.InitUploadService(5) // 5 workers para uploads
 
+	// Publica e arquiva produtos agendados
+	service.StartProductScheduler(time.Minute)
+
+	// Remove permanentemente itens antigos da lixeira
+	service.StartTrashPurger(24 * time.Hour)
+
+	// Grava em lote as visualizações de produtos acumuladas em memória
+	service.StartViewFlusher(time.Minute)
+
 	r := router.SetupRouter()
 
 	// Usa a porta do Render se disponível, senão 8080
@@ -23,6 +46,23 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("Servidor rodando na porta %s....", port)
-	r.Run(":" + port)
+	server := &http.Server{Addr: ":" + port, Handler: r}
+	go func() {
+		log.Printf("Servidor rodando na porta %s....", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Erro ao iniciar servidor: %v", err)
+		}
+	}()
+
+	// Ao receber SIGINT/SIGTERM, termina as requisições em andamento e grava as visualizações ainda em memória
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Erro ao encerrar servidor: %v", err)
+	}
+	service.FlushProductViews()
 }